@@ -0,0 +1,84 @@
+package webpagetest
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Response bodies are only kept when test was run with "bodies" or "htmlbody" (TestSettings.HTMLBody),
+// WebPagetest stores them in "<run>[_Cached][_<step>]_bodies.zip" with files named
+// like "003-2C5AD2D1-9F63-4E1D-B14D-AC8CE3B5F5C6-body.txt", where 003 is request number
+
+// GetResponseBody will retrieve body of response for request with given number
+// in given run, view (cached or not) and step. Run, step and requestNumber are 1-based.
+// Caller is responsible for closing returned body, download is cancelled when ctx is done
+func (c *Client) GetResponseBody(ctx context.Context, testID string, run int, cached bool, step int, requestNumber int) (io.ReadCloser, error) {
+	params := url.Values{
+		"test":    []string{testID},
+		"run":     []string{fmt.Sprintf("%d", run)},
+		"cached":  []string{"0"},
+		"step":    []string{fmt.Sprintf("%d", step)},
+		"request": []string{fmt.Sprintf("%d", requestNumber)},
+	}
+	if cached {
+		params.Set("cached", "1")
+	}
+
+	return c.download(ctx, "/response_body.php", params)
+}
+
+// GetRequestResponseBody will retrieve body of response for given request of test step
+func (c *Client) GetRequestResponseBody(ctx context.Context, testID string, step *TestStep, request Request) (io.ReadCloser, error) {
+	return c.GetResponseBody(ctx, testID, step.Run, step.Cached == 1, step.Step, request.Number)
+}
+
+// GetResponseBodies will download all stored bodies of text resources in given run, view and step
+// and returns them by request number
+func (c *Client) GetResponseBodies(ctx context.Context, testID string, run int, cached bool, step int) (map[int][]byte, error) {
+	body, err := c.queryContext(ctx, "/getfile.php", url.Values{
+		"test": []string{testID},
+		"file": []string{resultFileName(run, cached, step, "bodies.zip")},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseResponseBodies(body)
+}
+
+func parseResponseBodies(archive []byte) (map[int][]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bodies archive: %v", err)
+	}
+
+	result := make(map[int][]byte, len(reader.File))
+	for _, file := range reader.File {
+		if !strings.HasSuffix(file.Name, "-body.txt") {
+			continue
+		}
+		number, err := strconv.Atoi(strings.SplitN(file.Name, "-", 2)[0])
+		if err != nil {
+			continue
+		}
+
+		f, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %v", file.Name, err)
+		}
+		body, err := ioutil.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", file.Name, err)
+		}
+		result[number] = body
+	}
+
+	return result, nil
+}
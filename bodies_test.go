@@ -0,0 +1,125 @@
+package webpagetest
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testBodiesArchive(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := archive.Create(name)
+		assert.Nil(t, err)
+		_, err = f.Write([]byte(content))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, archive.Close())
+	return buf.Bytes()
+}
+
+func TestGettingResponseBodies(t *testing.T) {
+	archive := testBodiesArchive(t, map[string]string{
+		"001-2C5AD2D1-9F63-4E1D-B14D-AC8CE3B5F5C6-body.txt": "<html></html>",
+		"003-9F634E1D-B14D-AC8C-E3B5-F5C62C5AD2D1-body.txt": "body { color: red }",
+		"foo-1E4D9F63-B14D-AC8C-E3B5-F5C62C5AD2D1-body.txt": "bad name",
+		"002-1E4D9F63-B14D-AC8C-E3B5-F5C62C5AD2D1.txt":      "not a body",
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/getfile.php", r.URL.Path)
+		assert.Equal(t, "170101_AB_1", r.URL.Query().Get("test"))
+		assert.Equal(t, "2_Cached_3_bodies.zip", r.URL.Query().Get("file"))
+		w.Write(archive)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(server.URL)
+	bodies, err := client.GetResponseBodies(context.Background(), "170101_AB_1", 2, true, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, map[int][]byte{
+		1: []byte("<html></html>"),
+		3: []byte("body { color: red }"),
+	}, bodies)
+
+	// Request 2 has no stored body
+	_, ok := bodies[2]
+	assert.False(t, ok)
+}
+
+func TestGettingResponseBodiesFromBadArchive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("not a zip"))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(server.URL)
+	_, err := client.GetResponseBodies(context.Background(), "170101_AB_1", 1, false, 1)
+	assert.NotNil(t, err)
+}
+
+func TestGettingResponseBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/response_body.php", r.URL.Path)
+		query := r.URL.Query()
+		assert.Equal(t, "170101_AB_1", query.Get("test"))
+		assert.Equal(t, "1", query.Get("run"))
+		assert.Equal(t, "1", query.Get("cached"))
+		assert.Equal(t, "2", query.Get("step"))
+		if query.Get("request") != "5" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("<html></html>"))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(server.URL)
+	step := &TestStep{Run: 1, Cached: 1, Step: 2}
+
+	body, err := client.GetRequestResponseBody(context.Background(), "170101_AB_1", step, Request{Number: 5})
+	assert.Nil(t, err)
+	content, _ := ioutil.ReadAll(body)
+	body.Close()
+	assert.Equal(t, "<html></html>", string(content))
+
+	_, err = client.GetRequestResponseBody(context.Background(), "170101_AB_1", step, Request{Number: 6})
+	assert.NotNil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.GetResponseBody(ctx, "170101_AB_1", 1, true, 2, 5)
+	assert.NotNil(t, err)
+}
+
+func TestDecodingRequests(t *testing.T) {
+	step := &TestStep{RawRequests: []byte(`[
+		{"number": 1, "index": 0, "full_url": "http://example.com/", "responseCode": "200", "bytesIn": "467"},
+		{"number": 2, "index": 1, "full_url": "http://example.com/app.css", "responseCode": 404, "bytesIn": 120}
+	]`)}
+	requests, err := step.Requests()
+	assert.Nil(t, err)
+	assert.Len(t, requests, 2)
+	assert.Equal(t, 1, requests[0].Number)
+	assert.Equal(t, "http://example.com/app.css", requests[1].FullURL)
+	assert.Equal(t, 467, requests[0].BytesIn)
+	assert.Equal(t, 200, requests[0].ResponseCode)
+	assert.Equal(t, 404, requests[1].ResponseCode)
+
+	// Steps without requests=1 have only number of requests
+	step = &TestStep{RawRequests: []byte(`5`)}
+	requests, err = step.Requests()
+	assert.Nil(t, err)
+	assert.Empty(t, requests)
+
+	step = &TestStep{RawRequests: []byte(`[{"number": "one"}]`)}
+	_, err = step.Requests()
+	assert.NotNil(t, err)
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/docopt/docopt-go"
@@ -24,15 +25,22 @@ Usage:
   webpagetest testers [--server=<url>]
   webpagetest status <testID> [--server=<url>]
   webpagetest cancel <testID> [--server=<url>]
-  webpagetest results <testID> [--server=<url>] [--step=<stepIdx>]
+  webpagetest results (<testID> | --file=<path>) [--server=<url>] [--step=<stepIdx>] [--metric=<metric>]
+  webpagetest compare <baseline> <candidate> [--server=<url>] [--files] [--metrics=<metrics>] [--alpha=<alpha>] [--repeat-view]
   webpagetest -h | --help
   webpagetest --version
 
 Options:
-  -h --help         Show this screen.
-  --version         Show version.
-  --server=<url>    URL of private instance of WebPagetest Server
-  --step=<stepIdx>  Index of test step to use as source of metrics (1-based)`
+  -h --help            Show this screen.
+  --version            Show version.
+  --server=<url>       URL of private instance of WebPagetest Server
+  --step=<stepIdx>     Index of test step to use as source of metrics (1-based)
+  --file=<path>        Read result from saved jsonResult.php response or its "data" section, "-" for stdin
+  --metric=<metric>    Metric to select median run by, like "SpeedIndex", "userTime.<name>" or custom metric [default: loadTime]
+  --files              Treat <baseline> and <candidate> as paths to saved results, not test IDs
+  --metrics=<metrics>  Comma-separated metrics to compare, like "loadTime,SpeedIndex"
+  --alpha=<alpha>      Significance level of comparison [default: 0.05]
+  --repeat-view        Compare repeat views too`
 
 	arguments, _ := docopt.Parse(usage, nil, true, "WebPagetest CLI 1.0", false)
 
@@ -67,7 +75,38 @@ Options:
 			fmt.Printf("Will use step #%d\n", step)
 		}
 
-		getResults(arguments["<testID>"].(string), step)
+		metric := "loadTime"
+		if arguments["--metric"] != nil && arguments["--metric"].(string) != "" {
+			metric = arguments["--metric"].(string)
+		}
+
+		if arguments["--file"] != nil && arguments["--file"].(string) != "" {
+			printResults(loadResults(arguments["--file"].(string)), step, metric)
+		} else {
+			getResults(arguments["<testID>"].(string), step, metric)
+		}
+	}
+
+	if arguments["compare"].(bool) {
+		options := webpagetest.CompareOptions{RepeatView: arguments["--repeat-view"].(bool)}
+		if arguments["--metrics"] != nil && arguments["--metrics"].(string) != "" {
+			options.Metrics = strings.Split(arguments["--metrics"].(string), ",")
+		}
+		if arguments["--alpha"] != nil && arguments["--alpha"].(string) != "" {
+			alpha, err := strconv.ParseFloat(arguments["--alpha"].(string), 64)
+			if err != nil || alpha <= 0 || alpha >= 1 {
+				fmt.Printf("Error: --alpha must be a number between 0 and 1, got %q\n", arguments["--alpha"].(string))
+				os.Exit(2)
+			}
+			options.Alpha = alpha
+		}
+
+		baseline, candidate := arguments["<baseline>"].(string), arguments["<candidate>"].(string)
+		if arguments["--files"].(bool) {
+			compareResults(loadResults(baseline), loadResults(candidate), options)
+		} else {
+			compareResults(fetchResults(baseline), fetchResults(candidate), options)
+		}
 	}
 
 	// TODO: figure out how to specify all test params
@@ -151,18 +190,47 @@ func cancelTest(testID string) {
 	}
 }
 
-func getResults(testID string, testStep int64) {
+func getResults(testID string, testStep int64, metric string) {
 	// Test Result
 	// 161124_CC_3 - google.com
 	// 161122_K9_A - novosibirsk.n1.ru
 	// 161118_62_db87f3f04fe6b52b8cf4481fcf32cc0a
 	// 161126_19_12569a3f0de7a2fec98475b5d8bb0d37 (google.cz)
+	printResults(fetchResults(testID), testStep, metric)
+}
+
+// Get result of test from server
+func fetchResults(testID string) *webpagetest.ResultData {
 	result, err := wpt.GetTestResult(testID)
 	if err != nil {
 		fmt.Printf("Error: %v", err)
 		os.Exit(2)
 	}
+	return result
+}
+
+// Load result from file or stdin
+func loadResults(path string) *webpagetest.ResultData {
+	input := os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			fmt.Printf("Error: %v", err)
+			os.Exit(2)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	result, err := webpagetest.ParseResult(input)
+	if err != nil {
+		fmt.Printf("Failed to parse %s: %v", path, err)
+		os.Exit(2)
+	}
+	return result
+}
 
+func printResults(result *webpagetest.ResultData, testStep int64, metric string) {
 	fmt.Printf("ID: %v\n", result.ID)
 	fmt.Printf("URL: %v\n", result.URL)
 	fmt.Printf("Summary: %v\n", result.Summary)
@@ -202,14 +270,73 @@ func getResults(testID string, testStep int64) {
 		testStep = 1
 	}
 
-	medianRun, err := result.GetMedianRun(int(testStep-1), "loadtime")
+	medianRun, err := result.GetMedianRun(int(testStep-1), metric)
 	if err != nil {
 		fmt.Printf("GetMedianRun failed: %v\n", err)
+		os.Exit(2)
 	}
-	fmt.Printf("\nMedian run\n")
+	fmt.Printf("\nMedian run by %s\n", metric)
 	fmt.Println(stepAsTableRow(&medianRun.FirstView.Steps[testStep-1], true,
 		fmt.Sprintf("Run: #%d/%d ", medianRun.FirstView.Run, medianRun.RepeatView.Run)))
-	fmt.Println(stepAsTableRow(&medianRun.RepeatView.Steps[testStep-1], false, ""))
+	if len(medianRun.RepeatView.Steps) >= int(testStep) {
+		fmt.Println(stepAsTableRow(&medianRun.RepeatView.Steps[testStep-1], false, ""))
+	}
+
+	printUserTimes("First View", &medianRun.FirstView.Steps[testStep-1])
+	if len(medianRun.RepeatView.Steps) >= int(testStep) {
+		printUserTimes("Repeat View", &medianRun.RepeatView.Steps[testStep-1])
+	}
+}
+
+// Compare two tests
+func compareResults(baseline, candidate *webpagetest.ResultData, options webpagetest.CompareOptions) {
+	comparison, err := webpagetest.Compare(baseline, candidate, options)
+	if err != nil {
+		fmt.Printf("Error: %v", err)
+		os.Exit(2)
+	}
+
+	fmt.Printf("Baseline: %s (%d runs), candidate: %s (%d runs)\n",
+		baseline.ID, len(baseline.Runs), candidate.ID, len(candidate.Runs))
+	fmt.Printf("%-25s %4s %-6s %12s %12s %12s %9s %8s  %s\n",
+		"Metric", "Step", "View", "Baseline", "Candidate", "Delta", "Delta %", "p-value", "Verdict")
+	for _, metric := range comparison.Metrics {
+		view := "First"
+		if metric.Cached {
+			view = "Repeat"
+		}
+		fmt.Printf("%-25s %4d %-6s %12.1f %12.1f %+12.1f %+8.1f%% %8.4f  %v\n",
+			metric.Metric, metric.Step+1, view, metric.Baseline.P50, metric.Candidate.P50,
+			metric.Delta, metric.DeltaPercent, metric.PValue, metric.Verdict)
+	}
+}
+
+func printUserTimes(title string, ts *webpagetest.TestStep) {
+	if len(ts.UserTimes) == 0 && len(ts.UserTimingMeasures) == 0 {
+		return
+	}
+
+	fmt.Printf("\nUser Timing (%s)\n", title)
+	marks := make([]string, 0, len(ts.UserTimes))
+	for name := range ts.UserTimes {
+		marks = append(marks, name)
+	}
+	sort.Strings(marks)
+	for _, name := range marks {
+		fmt.Printf("  %-40s %v\n", name, time.Duration(ts.UserTimes[name]*float64(time.Millisecond)))
+	}
+
+	measures := make([]string, 0, len(ts.UserTimingMeasures))
+	for name := range ts.UserTimingMeasures {
+		measures = append(measures, name)
+	}
+	sort.Strings(measures)
+	for _, name := range measures {
+		measure := ts.UserTimingMeasures[name]
+		fmt.Printf("  %-40s %v (from %v)\n", name,
+			time.Duration(measure.Duration*float64(time.Millisecond)),
+			time.Duration(measure.StartTime*float64(time.Millisecond)))
+	}
 }
 
 func stepAsTableRow(ts *webpagetest.TestStep, header bool, headerTitle string) string {
@@ -0,0 +1,290 @@
+package webpagetest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Verdict is result of comparison of metric between baseline and candidate tests
+type Verdict int
+
+// Possible verdicts of comparison
+const (
+	VerdictInconclusive Verdict = iota
+	VerdictImproved
+	VerdictRegressed
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case VerdictInconclusive:
+		return "inconclusive"
+	case VerdictImproved:
+		return "improved"
+	case VerdictRegressed:
+		return "regressed"
+	}
+	return fmt.Sprintf("Verdict(%d)", int(v))
+}
+
+// DefaultCompareMetrics are metrics, that Compare uses if none are given
+var DefaultCompareMetrics = []string{
+	"TTFB", "render", "firstContentfulPaint", "SpeedIndex", "loadTime", "fullyLoaded", "bytesIn", "requestsFull",
+}
+
+// CompareOptions is options for Compare
+type CompareOptions struct {
+	// Metrics to compare, DefaultCompareMetrics if empty
+	Metrics []string
+	// Metrics, that are better when they are higher, all other are better when lower
+	HigherIsBetter []string
+	// Compare repeat views too
+	RepeatView bool
+	// Significance level, 0.05 by default
+	Alpha float64
+	// Minimal change of median (in %), that is treated as meaningful
+	MinChangePercent float64
+}
+
+// MetricComparison is comparison of one metric in one step and view
+type MetricComparison struct {
+	Metric string
+	Step   int // 0-based
+	Cached bool
+
+	Baseline  *Stats
+	Candidate *Stats
+
+	// Difference of medians, candidate - baseline, absolute and in % of baseline
+	Delta        float64
+	DeltaPercent float64
+
+	// Mann-Whitney U statistic (smaller of two) and two-sided p-value
+	U      float64
+	PValue float64
+
+	Verdict Verdict
+}
+
+func (c MetricComparison) String() string {
+	view := "First View"
+	if c.Cached {
+		view = "Repeat View"
+	}
+	return fmt.Sprintf("%s step %d (%s): %.1f -> %.1f (%+.1f, %+.1f%%), p=%.4f: %v",
+		c.Metric, c.Step+1, view, c.Baseline.P50, c.Candidate.P50, c.Delta, c.DeltaPercent, c.PValue, c.Verdict)
+}
+
+// Comparison is result of Compare
+type Comparison struct {
+	BaselineID  string
+	CandidateID string
+	Metrics     []MetricComparison
+}
+
+// ByVerdict returns comparisons of metrics with given verdict
+func (c *Comparison) ByVerdict(verdict Verdict) []MetricComparison {
+	result := make([]MetricComparison, 0)
+	for _, metric := range c.Metrics {
+		if metric.Verdict == verdict {
+			result = append(result, metric)
+		}
+	}
+	return result
+}
+
+// Compare compares metrics of candidate test with baseline one for each step, that both tests have.
+// Medians of successful runs are compared and Mann-Whitney U test is used to check, that difference
+// is significant. Metrics, that neither test has, are skipped
+func Compare(baseline, candidate *ResultData, options CompareOptions) (*Comparison, error) {
+	if options.Alpha <= 0 || options.Alpha >= 1 {
+		options.Alpha = 0.05
+	}
+	metrics := options.Metrics
+	if len(metrics) == 0 {
+		metrics = DefaultCompareMetrics
+	}
+	higherIsBetter := make(map[string]bool)
+	for _, metric := range options.HigherIsBetter {
+		higherIsBetter[metric] = true
+	}
+
+	views := []bool{false}
+	if options.RepeatView {
+		views = append(views, true)
+	}
+
+	comparison := &Comparison{BaselineID: baseline.ID, CandidateID: candidate.ID}
+	steps := baseline.stepCount()
+	if candidateSteps := candidate.stepCount(); candidateSteps < steps {
+		steps = candidateSteps
+	}
+	for _, cached := range views {
+		for step := 0; step < steps; step++ {
+			for _, metric := range metrics {
+				baseStats, err := baseline.Stats(cached, step, metric, StatsOptions{})
+				if err != nil {
+					continue
+				}
+				candidateStats, err := candidate.Stats(cached, step, metric, StatsOptions{})
+				if err != nil {
+					continue
+				}
+				comparison.Metrics = append(comparison.Metrics,
+					compareMetric(metric, step, cached, baseStats, candidateStats, higherIsBetter[metric], options))
+			}
+		}
+	}
+
+	if len(comparison.Metrics) == 0 {
+		return nil, fmt.Errorf("tests %s and %s have no metrics to compare", baseline.ID, candidate.ID)
+	}
+	return comparison, nil
+}
+
+func compareMetric(metric string, step int, cached bool, baseline, candidate *Stats, higherIsBetter bool, options CompareOptions) MetricComparison {
+	result := MetricComparison{
+		Metric:    metric,
+		Step:      step,
+		Cached:    cached,
+		Baseline:  baseline,
+		Candidate: candidate,
+		Delta:     candidate.P50 - baseline.P50,
+	}
+	if baseline.P50 != 0 {
+		result.DeltaPercent = result.Delta / math.Abs(baseline.P50) * 100
+	}
+
+	// uBaseline is number of pairs, where baseline value is greater than candidate one
+	uBaseline, pValue := mannWhitneyU(baseline.Values, candidate.Values)
+	uCandidate := float64(len(baseline.Values)*len(candidate.Values)) - uBaseline
+	result.U = math.Min(uBaseline, uCandidate)
+	result.PValue = pValue
+
+	if pValue >= options.Alpha || math.Abs(result.DeltaPercent) < options.MinChangePercent || uBaseline == uCandidate {
+		result.Verdict = VerdictInconclusive
+		return result
+	}
+	// Candidate values tend to be lower, than baseline ones
+	lower := uBaseline > uCandidate
+	if lower != higherIsBetter {
+		result.Verdict = VerdictImproved
+	} else {
+		result.Verdict = VerdictRegressed
+	}
+	return result
+}
+
+// mannWhitneyU returns U statistic of first sample (number of pairs where value from a is
+// greater than value from b, ties count as half) and two-sided p-value of Mann-Whitney U test.
+// Exact distribution is used for small samples without ties, normal approximation otherwise
+func mannWhitneyU(a, b []float64) (float64, float64) {
+	n1, n2 := len(a), len(b)
+	type sample struct {
+		value float64
+		first bool
+	}
+	all := make([]sample, 0, n1+n2)
+	for _, value := range a {
+		all = append(all, sample{value, true})
+	}
+	for _, value := range b {
+		all = append(all, sample{value, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].value < all[j].value })
+
+	// Average ranks of tied values
+	var rankSum, tieCorrection float64
+	ties := false
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].value == all[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].first {
+				rankSum += rank
+			}
+		}
+		if count := float64(j - i); count > 1 {
+			ties = true
+			tieCorrection += count*count*count - count
+		}
+		i = j
+	}
+	u := rankSum - float64(n1*(n1+1))/2
+
+	if !ties && n1 <= 20 && n2 <= 20 {
+		return u, exactMannWhitneyP(n1, n2, u)
+	}
+
+	n := float64(n1 + n2)
+	mean := float64(n1*n2) / 2
+	variance := float64(n1*n2) / 12 * ((n + 1) - tieCorrection/(n*(n-1)))
+	if variance <= 0 {
+		return u, 1
+	}
+	// With continuity correction
+	z := (math.Abs(u-mean) - 0.5) / math.Sqrt(variance)
+	if z < 0 {
+		z = 0
+	}
+	return u, math.Min(1, math.Erfc(z/math.Sqrt2))
+}
+
+// exactMannWhitneyP returns two-sided p-value of U for samples of sizes n1 and n2 without ties
+func exactMannWhitneyP(n1, n2 int, u float64) float64 {
+	maxU := n1 * n2
+	// counts[i][j][k] is number of arrangements of i and j values with U = k,
+	// only two layers by i are kept
+	previous := make([][]float64, n2+1)
+	current := make([][]float64, n2+1)
+	for j := range previous {
+		previous[j] = make([]float64, maxU+1)
+		current[j] = make([]float64, maxU+1)
+		previous[j][0] = 1
+	}
+	for i := 1; i <= n1; i++ {
+		for j := 0; j <= n2; j++ {
+			for k := 0; k <= maxU; k++ {
+				// Largest value is from first sample: it's greater than all j values of second one
+				var count float64
+				if k >= j {
+					count = previous[j][k-j]
+				}
+				// Largest value is from second sample
+				if j > 0 {
+					count += current[j-1][k]
+				}
+				current[j][k] = count
+			}
+		}
+		previous, current = current, previous
+	}
+
+	counts := previous[n2]
+	var total, lower, upper float64
+	for k, count := range counts {
+		total += count
+		if float64(k) <= u {
+			lower += count
+		}
+		if float64(k) >= u {
+			upper += count
+		}
+	}
+	return math.Min(1, 2*math.Min(lower, upper)/total)
+}
+
+// stepCount returns number of steps in first view of test
+func (rd *ResultData) stepCount() int {
+	steps := 0
+	for _, run := range rd.Runs {
+		if len(run.FirstView.Steps) > steps {
+			steps = len(run.FirstView.Steps)
+		}
+	}
+	return steps
+}
@@ -0,0 +1,90 @@
+package webpagetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMannWhitneyU(t *testing.T) {
+	// Exact distribution
+	u, p := mannWhitneyU([]float64{1, 2, 3}, []float64{4, 5, 6})
+	assert.Equal(t, 0.0, u)
+	assert.InDelta(t, 0.1, p, 1e-9)
+
+	u, p = mannWhitneyU([]float64{6, 7, 8, 9, 10}, []float64{1, 2, 3, 4, 5})
+	assert.Equal(t, 25.0, u)
+	assert.InDelta(t, 2.0/252, p, 1e-9)
+
+	_, p = mannWhitneyU([]float64{1, 4, 5}, []float64{2, 3, 6})
+	assert.Equal(t, 1.0, p)
+
+	// Normal approximation with ties
+	u, p = mannWhitneyU([]float64{10, 12, 12, 14, 15, 16, 18, 20}, []float64{13, 15, 17, 19, 21, 22, 22, 24})
+	assert.Equal(t, 11.5, u)
+	assert.InDelta(t, 0.035285, p, 1e-6)
+
+	// All values are the same
+	_, p = mannWhitneyU([]float64{5, 5, 5}, []float64{5, 5, 5})
+	assert.Equal(t, 1.0, p)
+}
+
+func TestCompare(t *testing.T) {
+	baseline := testResult([]int{1000, 1100, 1050, 990, 1020, 1080, 1010, 1060, 1040}, nil)
+	baseline.ID = "baseline"
+
+	faster := testResult([]int{800, 850, 820, 810, 790, 860, 830, 840, 805}, nil)
+	comparison, err := Compare(baseline, faster, CompareOptions{Metrics: []string{"loadTime", "SpeedIndex"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "baseline", comparison.BaselineID)
+	assert.Len(t, comparison.Metrics, 2)
+
+	loadTime := comparison.Metrics[0]
+	assert.Equal(t, "loadTime", loadTime.Metric)
+	assert.Equal(t, 1040.0, loadTime.Baseline.P50)
+	assert.Equal(t, 820.0, loadTime.Candidate.P50)
+	assert.Equal(t, -220.0, loadTime.Delta)
+	assert.InDelta(t, -21.15, loadTime.DeltaPercent, 0.01)
+	assert.Equal(t, 0.0, loadTime.U)
+	assert.True(t, loadTime.PValue < 0.001)
+	assert.Equal(t, VerdictImproved, loadTime.Verdict)
+
+	// SpeedIndex is 0 in all runs
+	assert.Equal(t, VerdictInconclusive, comparison.Metrics[1].Verdict)
+	assert.Len(t, comparison.ByVerdict(VerdictImproved), 1)
+
+	// Same change is regression, if higher is better
+	comparison, err = Compare(baseline, faster, CompareOptions{Metrics: []string{"loadTime"}, HigherIsBetter: []string{"loadTime"}})
+	assert.Nil(t, err)
+	assert.Equal(t, VerdictRegressed, comparison.Metrics[0].Verdict)
+
+	// Too small change
+	comparison, err = Compare(baseline, faster, CompareOptions{Metrics: []string{"loadTime"}, MinChangePercent: 25})
+	assert.Nil(t, err)
+	assert.Equal(t, VerdictInconclusive, comparison.Metrics[0].Verdict)
+
+	slower := testResult([]int{1000, 1300, 1200, 1400, 1250, 1350, 1150, 1280, 1320}, nil)
+	comparison, err = Compare(baseline, slower, CompareOptions{Metrics: []string{"loadTime"}})
+	assert.Nil(t, err)
+	assert.Equal(t, VerdictRegressed, comparison.Metrics[0].Verdict)
+
+	noisy := testResult([]int{1050, 990, 1100, 1010, 1060, 1000, 1070, 1030, 1045}, nil)
+	comparison, err = Compare(baseline, noisy, CompareOptions{Metrics: []string{"loadTime"}})
+	assert.Nil(t, err)
+	assert.Equal(t, VerdictInconclusive, comparison.Metrics[0].Verdict)
+	assert.True(t, comparison.Metrics[0].PValue > 0.05)
+
+	_, err = Compare(baseline, faster, CompareOptions{Metrics: []string{"noSuchMetric"}})
+	assert.NotNil(t, err)
+}
+
+func TestCompareRepeatView(t *testing.T) {
+	baseline := testResult([]int{100, 110, 120}, []int{50, 55, 60})
+	candidate := testResult([]int{100, 110, 120}, nil)
+
+	comparison, err := Compare(baseline, candidate, CompareOptions{Metrics: []string{"loadTime"}, RepeatView: true})
+	assert.Nil(t, err)
+	// Candidate has no repeat view
+	assert.Len(t, comparison.Metrics, 1)
+	assert.False(t, comparison.Metrics[0].Cached)
+}
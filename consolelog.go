@@ -0,0 +1,93 @@
+package webpagetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+/*
+"consoleLog": [
+  {
+    "column": 96,
+    "level": "info",
+    "line": 15,
+    "source": "console-api",
+    "text": "[Application]: start",
+    "url": "https://arhangelsk.n1.ru/tracker.js"
+  },
+  ...
+]
+*/
+
+// ConsoleMessage is one message from browser's console, that was captured during test
+type ConsoleMessage struct {
+	Source string `json:"source"` // "console-api", "javascript", "network"
+	Level  string `json:"level"`  // "info", "warning", "error"
+	Text   string `json:"text"`
+	URL    string `json:"url"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// String gives human readable representation of console message
+func (m ConsoleMessage) String() string {
+	if m.URL == "" {
+		return fmt.Sprintf("[%s] %s", m.Level, m.Text)
+	}
+	return fmt.Sprintf("[%s] %s (%s:%d:%d)", m.Level, m.Text, m.URL, m.Line, m.Column)
+}
+
+// ConsoleLog is list of console messages of one test step
+type ConsoleLog []ConsoleMessage
+
+// ByLevel returns only messages with one of given levels, like "error" or "warning"
+func (cl ConsoleLog) ByLevel(levels ...string) ConsoleLog {
+	result := make(ConsoleLog, 0)
+	for _, message := range cl {
+		for _, level := range levels {
+			if message.Level == level {
+				result = append(result, message)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Errors is shortcut for ByLevel("error")
+func (cl ConsoleLog) Errors() ConsoleLog {
+	return cl.ByLevel("error")
+}
+
+// Unique returns console log without repeated messages, order of first occurrences is kept
+func (cl ConsoleLog) Unique() ConsoleLog {
+	result := make(ConsoleLog, 0)
+	seen := make(map[ConsoleMessage]bool)
+	for _, message := range cl {
+		if seen[message] {
+			continue
+		}
+		seen[message] = true
+		result = append(result, message)
+	}
+	return result
+}
+
+// GetConsoleLog will retrieve console log of given run, view (cached or not) and step.
+// Run and step are 1-based
+func (c *Client) GetConsoleLog(testID string, run int, cached bool, step int) (ConsoleLog, error) {
+	body, err := c.query("/getgzip.php", url.Values{
+		"test": []string{testID},
+		"file": []string{resultFileName(run, cached, step, "console_log.json")},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result ConsoleLog
+	if err = json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,209 @@
+package webpagetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// https://sites.google.com/a/webpagetest.org/docs/using-webpagetest/custom-metrics
+
+/*
+[iframe-count]
+return document.getElementsByTagName("iframe").length;
+[has-service-worker]
+return 'serviceWorker' in navigator;
+*/
+
+// CustomMetricType is type of value, that custom metric's javascript returns
+type CustomMetricType int
+
+// Types of custom metric values
+const (
+	CustomMetricNumber CustomMetricType = iota
+	CustomMetricString
+	CustomMetricBool
+	CustomMetricJSON
+)
+
+func (t CustomMetricType) String() string {
+	switch t {
+	case CustomMetricNumber:
+		return "number"
+	case CustomMetricString:
+		return "string"
+	case CustomMetricBool:
+		return "bool"
+	case CustomMetricJSON:
+		return "json"
+	}
+	return fmt.Sprintf("CustomMetricType(%d)", int(t))
+}
+
+// CustomMetric is definition of custom metric: its name, javascript to collect it
+// at the end of the test and expected type of result
+type CustomMetric struct {
+	Name   string
+	Script string
+	Type   CustomMetricType
+}
+
+// CustomMetricValue is decoded value of custom metric in one test step,
+// only field that matches Type is set
+type CustomMetricValue struct {
+	Name   string
+	Type   CustomMetricType
+	Number float64
+	String string
+	Bool   bool
+	Raw    json.RawMessage
+}
+
+// CustomMetricError describes custom metric value that is missing or has unexpected type
+type CustomMetricError struct {
+	Name   string
+	Run    int
+	Step   int
+	Reason string
+}
+
+func (e *CustomMetricError) Error() string {
+	return fmt.Sprintf("custom metric %s (run %d, step %d): %s", e.Name, e.Run, e.Step, e.Reason)
+}
+
+// CustomMetricErrors is list of all problems with decoding of custom metrics of step
+type CustomMetricErrors []*CustomMetricError
+
+func (e CustomMetricErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+var customMetricName = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// CustomMetricRegistry is set of custom metrics, that can be rendered to TestSettings.CustomMetrics
+// and decoded from test results
+type CustomMetricRegistry struct {
+	metrics []CustomMetric
+}
+
+// Register adds new custom metric to registry
+func (r *CustomMetricRegistry) Register(name, script string, metricType CustomMetricType) error {
+	if !customMetricName.MatchString(name) {
+		return fmt.Errorf("invalid custom metric name %q", name)
+	}
+	// Values of known fields are decoded into TestStep itself and never get to Extra
+	testStepFields()
+	if _, ok := testStepFieldLower[strings.ToLower(name)]; ok {
+		return fmt.Errorf("custom metric name %s conflicts with test step field", name)
+	}
+	if strings.TrimSpace(script) == "" {
+		return fmt.Errorf("custom metric %s has no script", name)
+	}
+	for _, line := range strings.Split(script, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "[") && strings.HasSuffix(strings.TrimSpace(line), "]") {
+			return fmt.Errorf("custom metric %s: script line %q looks like metric name", name, line)
+		}
+	}
+	if _, ok := r.Get(name); ok {
+		return fmt.Errorf("custom metric %s is already registered", name)
+	}
+
+	r.metrics = append(r.metrics, CustomMetric{Name: name, Script: script, Type: metricType})
+	return nil
+}
+
+// Get returns custom metric by name
+func (r *CustomMetricRegistry) Get(name string) (CustomMetric, bool) {
+	for _, metric := range r.metrics {
+		if metric.Name == name {
+			return metric, true
+		}
+	}
+	return CustomMetric{}, false
+}
+
+// Metrics returns all registered custom metrics in order of registration
+func (r *CustomMetricRegistry) Metrics() []CustomMetric {
+	return append([]CustomMetric(nil), r.metrics...)
+}
+
+// String renders all metrics in "[name]\njavascript" format, that "custom" parameter expects
+func (r *CustomMetricRegistry) String() string {
+	parts := make([]string, 0, len(r.metrics))
+	for _, metric := range r.metrics {
+		parts = append(parts, "["+metric.Name+"]\n"+strings.TrimSpace(metric.Script))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Apply sets registered metrics as custom metrics of given settings
+func (r *CustomMetricRegistry) Apply(settings *TestSettings) {
+	settings.CustomMetrics = r.String()
+}
+
+// Decode returns values of all registered metrics from test step. If some of them
+// are missing or have unexpected type, valid ones are still returned along with CustomMetricErrors
+func (r *CustomMetricRegistry) Decode(step *TestStep) (map[string]CustomMetricValue, error) {
+	result := make(map[string]CustomMetricValue, len(r.metrics))
+	var errs CustomMetricErrors
+	for _, metric := range r.metrics {
+		raw, ok := step.Extra[metric.Name]
+		if !ok {
+			errs = append(errs, &CustomMetricError{metric.Name, step.Run, step.Step, "value is missing"})
+			continue
+		}
+
+		value, err := decodeCustomMetric(metric, raw)
+		if err != nil {
+			errs = append(errs, &CustomMetricError{metric.Name, step.Run, step.Step, err.Error()})
+			continue
+		}
+		result[metric.Name] = value
+	}
+
+	if len(errs) > 0 {
+		return result, errs
+	}
+	return result, nil
+}
+
+func decodeCustomMetric(metric CustomMetric, raw json.RawMessage) (CustomMetricValue, error) {
+	value := CustomMetricValue{Name: metric.Name, Type: metric.Type, Raw: raw}
+	text := rawString(raw)
+
+	var err error
+	switch metric.Type {
+	case CustomMetricNumber:
+		if value.Number, err = strconv.ParseFloat(strings.TrimSpace(text), 64); err != nil {
+			return value, fmt.Errorf("expected number, got %s", raw)
+		}
+	case CustomMetricString:
+		value.String = text
+	case CustomMetricBool:
+		switch strings.ToLower(strings.TrimSpace(text)) {
+		case "1", "true":
+			value.Bool = true
+		case "0", "false", "":
+			value.Bool = false
+		default:
+			return value, fmt.Errorf("expected bool, got %s", raw)
+		}
+	case CustomMetricJSON:
+		// WebPagetest may return objects as json encoded strings
+		var encoded string
+		if json.Unmarshal(raw, &encoded) == nil && json.Valid([]byte(encoded)) {
+			value.Raw = json.RawMessage(encoded)
+		} else if !json.Valid(raw) {
+			return value, fmt.Errorf("expected json, got %s", raw)
+		}
+	default:
+		return value, fmt.Errorf("unknown type %v", metric.Type)
+	}
+	return value, nil
+}
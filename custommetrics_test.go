@@ -0,0 +1,57 @@
+package webpagetest
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomMetricRegistry(t *testing.T) {
+	var registry CustomMetricRegistry
+	assert.Nil(t, registry.Register("iframe-count", `return document.getElementsByTagName("iframe").length;`, CustomMetricNumber))
+	assert.Nil(t, registry.Register("has-sw", `return 'serviceWorker' in navigator;`, CustomMetricBool))
+	assert.Nil(t, registry.Register("generator", `return document.querySelector("meta[name=generator]").content;`, CustomMetricString))
+	assert.Nil(t, registry.Register("sizes", `return JSON.stringify({w: innerWidth});`, CustomMetricJSON))
+	assert.Nil(t, registry.Register("missing", `return 1;`, CustomMetricNumber))
+	assert.NotNil(t, registry.Register("iframe-count", `return 1;`, CustomMetricNumber))
+	assert.NotNil(t, registry.Register("bad name", `return 1;`, CustomMetricNumber))
+	assert.EqualError(t, registry.Register("LoadTime", `return 1;`, CustomMetricNumber),
+		"custom metric name LoadTime conflicts with test step field")
+
+	var settings TestSettings
+	registry.Apply(&settings)
+	assert.Contains(t, settings.CustomMetrics, "[iframe-count]\nreturn document.getElementsByTagName(\"iframe\").length;\n[has-sw]\n")
+
+	var step TestStep
+	assert.Nil(t, json.Unmarshal([]byte(`{
+		"run": 1, "step": 1, "loadTime": 1000,
+		"iframe-count": "3",
+		"has-sw": 1,
+		"generator": "WordPress",
+		"sizes": "{\"w\":1280}"
+	}`), &step))
+	assert.Equal(t, 1000, step.LoadTime)
+
+	values, err := registry.Decode(&step)
+	assert.Equal(t, "custom metric missing (run 1, step 1): value is missing", err.Error())
+	assert.Equal(t, 3.0, values["iframe-count"].Number)
+	assert.True(t, values["has-sw"].Bool)
+	assert.Equal(t, "WordPress", values["generator"].String)
+	assert.Equal(t, `{"w":1280}`, string(values["sizes"].Raw))
+
+	assert.Nil(t, json.Unmarshal([]byte(`{"run": 2, "step": 1, "iframe-count": "many", "has-sw": 0, "generator": "", "sizes": "{}", "missing": 0}`), &step))
+	values, err = registry.Decode(&step)
+	assert.Equal(t, `custom metric iframe-count (run 2, step 1): expected number, got "many"`, err.Error())
+	assert.False(t, values["has-sw"].Bool)
+	assert.Len(t, values, 4)
+
+	// Escapes are decoded as in JSON, not as in Go
+	assert.Nil(t, json.Unmarshal([]byte(`{"run": 3, "step": 1, "generator": "Hugo \/ \u00e9", "sizes": "{\"url\":\"https:\/\/example.com\/\"}"}`), &step))
+	values, _ = registry.Decode(&step)
+	assert.Equal(t, "Hugo / é", values["generator"].String)
+	assert.Equal(t, `{"url":"https://example.com/"}`, string(values["sizes"].Raw))
+	var sizes map[string]string
+	assert.Nil(t, json.Unmarshal(values["sizes"].Raw, &sizes))
+	assert.Equal(t, "https://example.com/", sizes["url"])
+}
@@ -0,0 +1,96 @@
+package webpagetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WebPagetest is not consistent in how it encodes values: same field can be
+// 0, "0", "" or null depending on server version and endpoint. Flex types
+// decode all of these forms and are encoded back as plain json values
+
+// FlexInt is int, that can be decoded from json number, numeric string,
+// "", null or true/false. Fractional numbers are truncated
+type FlexInt int
+
+// FlexFloat is float64, that can be decoded from json number, numeric string,
+// "", null or true/false
+type FlexFloat float64
+
+// FlexBool is bool, that can be decoded from true/false, 0/1, "0"/"1",
+// "on"/"off", "yes"/"no", "" or null. Any other non-empty value is true
+type FlexBool bool
+
+// UnmarshalJSON implements json.Unmarshaler
+func (i *FlexInt) UnmarshalJSON(b []byte) error {
+	value, err := parseFlexNumber(b)
+	if err != nil {
+		return err
+	}
+	*i = FlexInt(value)
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (f *FlexFloat) UnmarshalJSON(b []byte) error {
+	value, err := parseFlexNumber(b)
+	if err != nil {
+		return err
+	}
+	*f = FlexFloat(value)
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (f *FlexBool) UnmarshalJSON(b []byte) error {
+	text := strings.ToLower(flexText(b))
+	switch text {
+	case "", "null", "false", "off", "no":
+		*f = false
+		return nil
+	case "true", "on", "yes":
+		*f = true
+		return nil
+	}
+	if value, err := strconv.ParseFloat(text, 64); err == nil {
+		*f = value != 0
+		return nil
+	}
+	// Flag is set to some other value, like "Chrome" or "all"
+	*f = true
+	return nil
+}
+
+// parseFlexNumber decodes json number or string with number, empty and null values are 0
+func parseFlexNumber(b []byte) (float64, error) {
+	text := flexText(b)
+	switch strings.ToLower(text) {
+	case "", "null", "false":
+		return 0, nil
+	case "true":
+		return 1, nil
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, fmt.Errorf("can't decode %s as number", b)
+	}
+	return value, nil
+}
+
+// flexText returns json value as text, strings are unquoted and trimmed
+func flexText(b []byte) string {
+	return strings.TrimSpace(rawString(b))
+}
+
+// rawString returns value of json string unquoted, other values are returned as is
+func rawString(raw json.RawMessage) string {
+	value := bytes.TrimSpace(raw)
+	var text string
+	if len(value) > 0 && value[0] == '"' && json.Unmarshal(value, &text) == nil {
+		return text
+	}
+	return string(value)
+}
@@ -0,0 +1,127 @@
+package webpagetest
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlexInt(t *testing.T) {
+	for input, expected := range map[string]FlexInt{
+		`0`:      0,
+		`"0"`:    0,
+		`""`:     0,
+		`null`:   0,
+		`42`:     42,
+		`"42"`:   42,
+		`" 7 "`:  7,
+		`-1`:     -1,
+		`"12.9"`: 12,
+		`true`:   1,
+	} {
+		var value FlexInt
+		assert.Nil(t, json.Unmarshal([]byte(input), &value), input)
+		assert.Equal(t, expected, value, input)
+	}
+
+	var value FlexInt
+	assert.NotNil(t, json.Unmarshal([]byte(`"abc"`), &value))
+	assert.NotNil(t, json.Unmarshal([]byte(`[1]`), &value))
+}
+
+func TestFlexFloat(t *testing.T) {
+	for input, expected := range map[string]FlexFloat{
+		`""`:      0,
+		`null`:    0,
+		`14.729`:  14.729,
+		`"5.428"`: 5.428,
+		`"-1"`:    -1,
+	} {
+		var value FlexFloat
+		assert.Nil(t, json.Unmarshal([]byte(input), &value), input)
+		assert.Equal(t, expected, value, input)
+	}
+}
+
+func TestFlexBool(t *testing.T) {
+	for input, expected := range map[string]FlexBool{
+		`true`:    true,
+		`false`:   false,
+		`1`:       true,
+		`0`:       false,
+		`"1"`:     true,
+		`"0"`:     false,
+		`"on"`:    true,
+		`"off"`:   false,
+		`""`:      false,
+		`null`:    false,
+		`"true"`:  true,
+		`"False"`: false,
+	} {
+		var value FlexBool
+		assert.Nil(t, json.Unmarshal([]byte(input), &value), input)
+		assert.Equal(t, expected, value, input)
+	}
+
+	var value FlexBool
+	assert.Nil(t, json.Unmarshal([]byte(`"maybe"`), &value))
+	assert.Equal(t, FlexBool(true), value)
+}
+
+func TestFlexMarshal(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Int   FlexInt
+		Float FlexFloat
+		Bool  FlexBool
+	}{42, 1.5, true})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"Int":42,"Float":1.5,"Bool":true}`, string(data))
+}
+
+func TestFlexRequest(t *testing.T) {
+	var request Request
+	assert.Nil(t, json.Unmarshal([]byte(`{
+		"request_id": "9", "type": 3, "bytesIn": "467", "objectSize": "",
+		"is_secure": "1", "was_pushed": 0, "ttfb_ms": "43", "load_ms": 12.5, "dns_ms": null
+	}`), &request))
+	assert.Equal(t, 9, request.RequestID)
+	assert.Equal(t, 3, request.Type)
+	assert.Equal(t, 467, request.BytesIn)
+	assert.Equal(t, 0, request.ObjectSize)
+	assert.True(t, request.IsSecure)
+	assert.False(t, request.WasPushed)
+	assert.Equal(t, 43.0, request.TTFB)
+	assert.Equal(t, 12.5, request.Load)
+	assert.Equal(t, 0.0, request.DNS)
+}
+
+func TestFlexTestStatus(t *testing.T) {
+	var status TestStatus
+	assert.Nil(t, json.Unmarshal([]byte(`{
+		"statusCode": 100, "testId": "170101_AB_1", "runs": "3", "fvonly": "1",
+		"remote": false, "elapsed": 24, "testsExpected": "3", "fvRunsCompleted": 2,
+		"testInfo": {"runs": 3, "mobile": "1"}
+	}`), &status))
+	assert.Equal(t, 100, status.StatusCode)
+	assert.Equal(t, "170101_AB_1", status.TestID)
+	assert.Equal(t, 3, status.Runs)
+	assert.True(t, status.FirstViewOnly)
+	assert.False(t, status.Remote)
+	assert.Equal(t, 24, status.Elapsed)
+	assert.Equal(t, 3, status.TestsExpected)
+	assert.Equal(t, 2, status.FirstViewRunsCompleted)
+	assert.True(t, status.TestInfo.Mobile)
+}
+
+func TestRawString(t *testing.T) {
+	assert.Equal(t, "WordPress 5.0/x", rawString(json.RawMessage(`"WordPress 5.0\/x"`)))
+	assert.Equal(t, " padded ", rawString(json.RawMessage(` " padded " `)))
+	assert.Equal(t, "42.5", rawString(json.RawMessage(`42.5`)))
+	assert.Equal(t, `{"a":1}`, rawString(json.RawMessage(`{"a":1}`)))
+
+	step := TestStep{Extra: map[string]json.RawMessage{"generator": json.RawMessage(`"WordPress 5.0\/x"`)}}
+	text, ok := step.ExtraString("generator")
+	assert.True(t, ok)
+	assert.Equal(t, "WordPress 5.0/x", text)
+}
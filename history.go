@@ -0,0 +1,174 @@
+package webpagetest
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// testlog.php?f=csv&days=1&from=2016-11-26&nolimit=1&all=on
+
+/*
+Date/Time,Location,Test ID,URL,Label
+11/26/16 7:17:53,Prague,161126_19_12569a3f0de7a2fec98475b5d8bb0d37,http://google.com,test run
+11/26/16 7:10:02,Dulles,161126_KQ_2,http://ngs.ru,
+*/
+
+// HistoryQuery is filter for test history
+type HistoryQuery struct {
+	// Number of days to look back (1)
+	Days int
+	// Only tests with URL that contains given string
+	URL string
+	// Only tests with given label, test log can't filter by label, so it's done on client
+	Label string
+	// Only tests from given location, like "Dulles" or "Dulles:Chrome",
+	// test log can't filter by location, so it's done on client
+	Location string
+	// Only tests that were submitted with API key of this client,
+	// otherwise tests of all users are returned
+	OnlyMine bool
+	// Time zone of server, dates in test log have no zone (UTC)
+	TimeZone *time.Location
+}
+
+// HistoryEntry is one test from test history
+type HistoryEntry struct {
+	Date     time.Time
+	RawDate  string
+	Location string
+	TestID   string
+	URL      string
+	Label    string
+}
+
+// History is list of tests from test log, newest first
+type History []HistoryEntry
+
+// historyDateLayouts are formats of date that test log used over time
+var historyDateLayouts = []string{
+	"01/02/06 15:04:05",
+	"1/2/06 15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+// GetHistory will retrieve list of tests from server's test log. Test log is requested
+// day by day, so large histories are fetched in small pages. Only URL is filtered by server,
+// other fields of query are checked on client side
+func (c *Client) GetHistory(ctx context.Context, query HistoryQuery) (History, error) {
+	days := query.Days
+	if days < 1 {
+		days = 1
+	}
+	if query.OnlyMine && c.APIKey == "" {
+		return nil, fmt.Errorf("API key is required to get only own tests")
+	}
+
+	result := make(History, 0)
+	now := time.Now()
+	for day := 0; day < days; day++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		params := url.Values{
+			"f":       []string{"csv"},
+			"days":    []string{"1"},
+			"from":    []string{now.AddDate(0, 0, -day).Format("2006-01-02")},
+			"nolimit": []string{"1"},
+		}
+		if query.URL != "" {
+			params.Set("filter", query.URL)
+		}
+		if c.APIKey != "" {
+			params.Set("k", c.APIKey)
+		}
+		if !query.OnlyMine {
+			params.Set("all", "on")
+		}
+
+		body, err := c.queryContext(ctx, "/testlog.php", params)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := parseHistory(bytes.NewReader(body), query.TimeZone)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, entries.Filter(query)...)
+	}
+
+	return result, nil
+}
+
+// Filter returns only entries that match given query, Days is ignored
+func (h History) Filter(query HistoryQuery) History {
+	result := make(History, 0, len(h))
+	for _, entry := range h {
+		if query.URL != "" && !strings.Contains(entry.URL, query.URL) {
+			continue
+		}
+		if query.Label != "" && entry.Label != query.Label {
+			continue
+		}
+		if query.Location != "" && entry.Location != query.Location &&
+			!strings.HasPrefix(entry.Location, query.Location+":") {
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+// parseHistory parses test log in csv format, dates are in given time zone (UTC if nil)
+func parseHistory(r io.Reader, zone *time.Location) (History, error) {
+	if zone == nil {
+		zone = time.UTC
+	}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	result := make(History, 0)
+	header := true
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse test log: %v", err)
+		}
+		if header {
+			header = false
+			if strings.HasPrefix(csvField(record, 0), "Date") {
+				continue
+			}
+		}
+		if len(record) < 4 {
+			continue
+		}
+
+		entry := HistoryEntry{
+			RawDate:  csvField(record, 0),
+			Location: csvField(record, 1),
+			TestID:   csvField(record, 2),
+			URL:      csvField(record, 3),
+			Label:    csvField(record, 4),
+		}
+		for _, layout := range historyDateLayouts {
+			if date, err := time.ParseInLocation(layout, entry.RawDate, zone); err == nil {
+				entry.Date = date
+				break
+			}
+		}
+		result = append(result, entry)
+	}
+
+	return result, nil
+}
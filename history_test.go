@@ -0,0 +1,74 @@
+package webpagetest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testHistory = `Date/Time,Location,Test ID,URL,Label
+11/26/16 7:17:53,Prague,161126_19_12569a3f0de7a2fec98475b5d8bb0d37,http://google.com,test run
+11/26/16 17:10:02,Dulles:Chrome,161126_KQ_2,http://ngs.ru,
+`
+
+func TestParsingHistory(t *testing.T) {
+	history, err := parseHistory(strings.NewReader(testHistory), nil)
+	assert.Nil(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, "161126_19_12569a3f0de7a2fec98475b5d8bb0d37", history[0].TestID)
+	assert.Equal(t, "test run", history[0].Label)
+	assert.Equal(t, time.Date(2016, 11, 26, 7, 17, 53, 0, time.UTC), history[0].Date)
+	assert.Equal(t, "", history[1].Label)
+
+	assert.Len(t, history.Filter(HistoryQuery{Label: "test run"}), 1)
+	assert.Len(t, history.Filter(HistoryQuery{URL: "ngs"}), 1)
+	assert.Len(t, history.Filter(HistoryQuery{Location: "Dulles"}), 1)
+	assert.Len(t, history.Filter(HistoryQuery{Location: "Prague:Chrome"}), 0)
+}
+
+func TestParsingHistoryInTimeZone(t *testing.T) {
+	zone := time.FixedZone("MSK", 3*60*60)
+	history, err := parseHistory(strings.NewReader(testHistory), zone)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2016, 11, 26, 4, 17, 53, 0, time.UTC), history[0].Date.UTC())
+	assert.Equal(t, zone, history[0].Date.Location())
+}
+
+func TestGettingHistory(t *testing.T) {
+	var queries []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/testlog.php", r.URL.Path)
+		queries = append(queries, r.URL.Query())
+		fmt.Fprint(w, testHistory)
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+
+	now := time.Now()
+	history, err := client.GetHistory(context.Background(), HistoryQuery{Days: 2, URL: "google", Location: "Prague"})
+	assert.Nil(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, []url.Values{
+		{"f": {"csv"}, "days": {"1"}, "from": {now.Format("2006-01-02")}, "nolimit": {"1"}, "filter": {"google"}, "all": {"on"}},
+		{"f": {"csv"}, "days": {"1"}, "from": {now.AddDate(0, 0, -1).Format("2006-01-02")}, "nolimit": {"1"}, "filter": {"google"}, "all": {"on"}},
+	}, queries)
+
+	_, err = client.GetHistory(context.Background(), HistoryQuery{OnlyMine: true})
+	assert.NotNil(t, err)
+
+	queries = nil
+	client.APIKey = "key"
+	history, err = client.GetHistory(context.Background(), HistoryQuery{OnlyMine: true})
+	assert.Nil(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, []url.Values{
+		{"f": {"csv"}, "days": {"1"}, "from": {now.Format("2006-01-02")}, "nolimit": {"1"}, "k": {"key"}},
+	}, queries)
+}
@@ -0,0 +1,170 @@
+package webpagetest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// ImageFormat is format of screenshot image
+type ImageFormat string
+
+// Available image formats, PNG screenshots are only available if test was run with TestSettings.PNGScreenShot
+const (
+	ImageJPEG ImageFormat = "jpg"
+	ImagePNG  ImageFormat = "png"
+)
+
+// ImageOptions selects for which run, view and step image should be retrieved
+type ImageOptions struct {
+	// Run number, 1-based (1)
+	Run int
+	// Repeat view instead of first view
+	Cached bool
+	// Step number, 1-based (1)
+	Step int
+}
+
+func (o ImageOptions) params(testID string) url.Values {
+	run, step := o.Run, o.Step
+	if run < 1 {
+		run = 1
+	}
+	if step < 1 {
+		step = 1
+	}
+
+	params := url.Values{
+		"test":   []string{testID},
+		"run":    []string{fmt.Sprintf("%d", run)},
+		"cached": []string{"0"},
+		"step":   []string{fmt.Sprintf("%d", step)},
+	}
+	if o.Cached {
+		params.Set("cached", "1")
+	}
+	return params
+}
+
+// WaterfallOptions is options for rendering of waterfall (or connection view) image
+type WaterfallOptions struct {
+	ImageOptions
+
+	// Render connection view instead of waterfall
+	ConnectionView bool
+	// PNG or JPEG (PNG)
+	Format ImageFormat
+	// Image width in pixels (930)
+	Width int
+	// Time in seconds to end waterfall at, 0 for auto
+	MaxTime float64
+	// Only show given requests, like "1-5,8,10"
+	Requests string
+	// Color requests by mime type instead of by timings
+	ColorByMime bool
+	// Show CPU utilization graph
+	ShowCPU bool
+	// Show bandwidth utilization graph
+	ShowBandwidth bool
+	// Show ellipsis for hidden requests
+	ShowDots bool
+	// Show URLs of requests
+	ShowLabels bool
+	// Show user timing marks
+	ShowUserTiming bool
+}
+
+func (o WaterfallOptions) params(testID string) url.Values {
+	params := o.ImageOptions.params(testID)
+	params.Set("type", "waterfall")
+	if o.ConnectionView {
+		params.Set("type", "connection")
+	}
+	if o.Format != "" {
+		params.Set("format", string(o.Format))
+	}
+	if o.Width > 0 {
+		params.Set("width", fmt.Sprintf("%d", o.Width))
+	}
+	if o.MaxTime > 0 {
+		params.Set("max", fmt.Sprintf("%g", o.MaxTime))
+	}
+	if o.Requests != "" {
+		params.Set("requests", o.Requests)
+	}
+
+	flags := map[string]bool{
+		"mime":   o.ColorByMime,
+		"cpu":    o.ShowCPU,
+		"bw":     o.ShowBandwidth,
+		"dots":   o.ShowDots,
+		"labels": o.ShowLabels,
+		"ut":     o.ShowUserTiming,
+	}
+	for name, enabled := range flags {
+		if enabled {
+			params.Set(name, "1")
+		} else {
+			params.Set(name, "0")
+		}
+	}
+	return params
+}
+
+// ScreenshotOptions is options for retrieving screenshot
+type ScreenshotOptions struct {
+	ImageOptions
+
+	// JPEG or PNG (JPEG)
+	Format ImageFormat
+	// Moment of the test, when screenshot was taken: "" for fully loaded,
+	// "render" for start render or "doc" for document complete
+	Moment string
+}
+
+func (o ScreenshotOptions) fileName() string {
+	format := o.Format
+	if format == "" {
+		format = ImageJPEG
+	}
+	run, step := o.Run, o.Step
+	if run < 1 {
+		run = 1
+	}
+
+	name := "screen"
+	if o.Moment != "" {
+		name += "_" + strings.ToLower(o.Moment)
+	}
+	return resultFileName(run, o.Cached, step, name+"."+string(format))
+}
+
+// GetWaterfallImage will render waterfall (or connection view) image with given options
+// and write it to w
+func (c *Client) GetWaterfallImage(w io.Writer, testID string, options WaterfallOptions) error {
+	body, err := c.download(context.Background(), "/waterfall.php", options.params(testID))
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	_, err = io.Copy(w, body)
+	return err
+}
+
+// GetScreenshotImage will retrieve screenshot with given options and write it to w
+func (c *Client) GetScreenshotImage(w io.Writer, testID string, options ScreenshotOptions) error {
+	body, err := c.download(context.Background(), "/getfile.php", url.Values{
+		"test": []string{testID},
+		"file": []string{options.fileName()},
+	})
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	_, err = io.Copy(w, body)
+	return err
+}
@@ -0,0 +1,75 @@
+package webpagetest
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGettingWaterfallImage(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/waterfall.php", r.URL.Path)
+		query = r.URL.Query()
+		w.Write([]byte("image"))
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+
+	var image bytes.Buffer
+	err := client.GetWaterfallImage(&image, "170101_AB_1", WaterfallOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "image", image.String())
+	assert.Equal(t, url.Values{
+		"test": {"170101_AB_1"}, "run": {"1"}, "cached": {"0"}, "step": {"1"}, "type": {"waterfall"},
+		"mime": {"0"}, "cpu": {"0"}, "bw": {"0"}, "dots": {"0"}, "labels": {"0"}, "ut": {"0"},
+	}, query)
+
+	err = client.GetWaterfallImage(&image, "170101_AB_1", WaterfallOptions{
+		ImageOptions:   ImageOptions{Run: 3, Cached: true, Step: 2},
+		ConnectionView: true,
+		Format:         ImageJPEG,
+		Width:          1200,
+		MaxTime:        2.5,
+		Requests:       "1-5,8",
+		ColorByMime:    true,
+		ShowCPU:        true,
+		ShowBandwidth:  true,
+		ShowDots:       true,
+		ShowLabels:     true,
+		ShowUserTiming: true,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, url.Values{
+		"test": {"170101_AB_1"}, "run": {"3"}, "cached": {"1"}, "step": {"2"}, "type": {"connection"},
+		"format": {"jpg"}, "width": {"1200"}, "max": {"2.5"}, "requests": {"1-5,8"},
+		"mime": {"1"}, "cpu": {"1"}, "bw": {"1"}, "dots": {"1"}, "labels": {"1"}, "ut": {"1"},
+	}, query)
+}
+
+func TestGettingScreenshotImage(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/getfile.php", r.URL.Path)
+		query = r.URL.Query()
+		w.Write([]byte("image"))
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+
+	var image bytes.Buffer
+	assert.Nil(t, client.GetScreenshotImage(&image, "170101_AB_1", ScreenshotOptions{}))
+	assert.Equal(t, url.Values{"test": {"170101_AB_1"}, "file": {"1_screen.jpg"}}, query)
+
+	options := ScreenshotOptions{
+		ImageOptions: ImageOptions{Run: 2, Cached: true, Step: 3},
+		Format:       ImagePNG,
+		Moment:       "Render",
+	}
+	assert.Nil(t, client.GetScreenshotImage(&image, "170101_AB_1", options))
+	assert.Equal(t, url.Values{"test": {"170101_AB_1"}, "file": {"2_Cached_3_screen_render.png"}}, query)
+}
@@ -0,0 +1,169 @@
+package webpagetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// getgzip.php?test=<testId>&file=lighthouse.json
+
+/*
+{
+  "lighthouseVersion": "6.4.1",
+  "requestedUrl": "https://www.google.com/",
+  "finalUrl": "https://www.google.com/",
+  "fetchTime": "2020-11-12T10:22:31.117Z",
+  "runtimeError": {"code": "NO_FCP", "message": "The page did not paint any content..."},
+  "categories": {
+    "performance": {"id": "performance", "title": "Performance", "score": 0.92},
+    ...
+  },
+  "audits": {
+    "largest-contentful-paint": {
+      "id": "largest-contentful-paint",
+      "title": "Largest Contentful Paint",
+      "score": 0.8,
+      "scoreDisplayMode": "numeric",
+      "numericValue": 2345.6,
+      "numericUnit": "millisecond",
+      "displayValue": "2.3 s"
+    },
+    ...
+  }
+}
+*/
+
+// LighthouseError is error of Lighthouse run, that happened while WebPagetest run itself could succeed
+type LighthouseError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *LighthouseError) Error() string {
+	return fmt.Sprintf("lighthouse failed: %s: %s", e.Code, e.Message)
+}
+
+// LighthouseCategory is score of one category, like "performance" or "accessibility"
+type LighthouseCategory struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// Score from 0 to 1, nil if category could not be scored
+	Score *float64 `json:"score"`
+}
+
+// LighthouseAudit is result of one Lighthouse audit
+type LighthouseAudit struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// Score from 0 to 1, nil for informative audits or if audit failed
+	Score            *float64 `json:"score"`
+	ScoreDisplayMode string   `json:"scoreDisplayMode"` // "numeric", "binary", "informative", "error"
+	NumericValue     float64  `json:"numericValue"`
+	NumericUnit      string   `json:"numericUnit"` // "millisecond", "unitless"
+	DisplayValue     string   `json:"displayValue"`
+	ErrorMessage     string   `json:"errorMessage"`
+}
+
+// LighthouseMetrics is main performance metrics from Lighthouse audits
+type LighthouseMetrics struct {
+	FirstContentfulPaint   float64 // ms
+	LargestContentfulPaint float64 // ms
+	CumulativeLayoutShift  float64 // unitless score
+	TotalBlockingTime      float64 // ms
+	SpeedIndex             float64 // ms
+	TimeToInteractive      float64 // ms
+}
+
+// LighthouseResult is parsed Lighthouse report (LHR)
+type LighthouseResult struct {
+	LighthouseVersion string `json:"lighthouseVersion"`
+	RequestedURL      string `json:"requestedUrl"`
+	FinalURL          string `json:"finalUrl"`
+	FetchTime         string `json:"fetchTime"`
+
+	// Set when Lighthouse failed to audit page
+	RuntimeError *LighthouseError `json:"runtimeError"`
+
+	Categories map[string]LighthouseCategory `json:"categories"`
+	Audits     map[string]LighthouseAudit    `json:"audits"`
+}
+
+// Failed reports if Lighthouse failed to audit page
+func (r *LighthouseResult) Failed() bool {
+	return r.RuntimeError != nil && r.RuntimeError.Code != "" && r.RuntimeError.Code != "NO_ERROR"
+}
+
+// Score returns score (0-100) of category with given id, like "performance",
+// second value is false if category is missing or wasn't scored
+func (r *LighthouseResult) Score(category string) (int, bool) {
+	c, ok := r.Categories[category]
+	if !ok || c.Score == nil {
+		return 0, false
+	}
+	return int(*c.Score*100 + 0.5), true
+}
+
+// Metrics returns main performance metrics, missing ones are 0
+func (r *LighthouseResult) Metrics() LighthouseMetrics {
+	return LighthouseMetrics{
+		FirstContentfulPaint:   r.Audits["first-contentful-paint"].NumericValue,
+		LargestContentfulPaint: r.Audits["largest-contentful-paint"].NumericValue,
+		CumulativeLayoutShift:  r.Audits["cumulative-layout-shift"].NumericValue,
+		TotalBlockingTime:      r.Audits["total-blocking-time"].NumericValue,
+		SpeedIndex:             r.Audits["speed-index"].NumericValue,
+		TimeToInteractive:      r.Audits["interactive"].NumericValue,
+	}
+}
+
+// GetLighthouseResult will retrieve Lighthouse report of test, that was run with TestSettings.Lighthouse.
+// If Lighthouse failed, report is still returned along with *LighthouseError from its RuntimeError.
+// If test has no report, *LighthouseError with code "NO_RESULT" is returned
+func (c *Client) GetLighthouseResult(testID string) (*LighthouseResult, error) {
+	body, err := c.query("/getgzip.php", url.Values{
+		"test": []string{testID},
+		"file": []string{"lighthouse.json"},
+	})
+	if statusErr, ok := err.(*statusError); ok && statusErr.Code == http.StatusNotFound {
+		// Missing report is handled as empty one
+		body, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, &LighthouseError{Code: "NO_RESULT", Message: fmt.Sprintf("test %s has no lighthouse result", testID)}
+	}
+
+	result, err := parseLighthouseResult(body)
+	if err != nil {
+		return nil, err
+	}
+	if result.Failed() {
+		return result, result.RuntimeError
+	}
+	return result, nil
+}
+
+// LighthouseResult returns Lighthouse report, if server included it in test result
+func (rd *ResultData) LighthouseResult() (*LighthouseResult, error) {
+	trimmed := bytes.TrimSpace(rd.RawLighthouse)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, fmt.Errorf("test %s has no lighthouse result", rd.ID)
+	}
+	return parseLighthouseResult(trimmed)
+}
+
+func parseLighthouseResult(body []byte) (*LighthouseResult, error) {
+	var result LighthouseResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse lighthouse result: %v", err)
+	}
+	if result.LighthouseVersion == "" && result.RuntimeError == nil && len(result.Audits) == 0 {
+		return nil, fmt.Errorf("unexpected lighthouse result: %.100s", string(body))
+	}
+	return &result, nil
+}
@@ -0,0 +1,82 @@
+package webpagetest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsingLighthouseResult(t *testing.T) {
+	result, err := parseLighthouseResult([]byte(`{
+	  "lighthouseVersion": "6.4.1",
+	  "finalUrl": "https://www.google.com/",
+	  "categories": {"performance": {"id": "performance", "title": "Performance", "score": 0.92}},
+	  "audits": {
+	    "largest-contentful-paint": {"id": "largest-contentful-paint", "score": 0.8, "numericValue": 2345.6},
+	    "cumulative-layout-shift": {"id": "cumulative-layout-shift", "score": 1, "numericValue": 0.012},
+	    "total-blocking-time": {"id": "total-blocking-time", "score": 0.9, "numericValue": 150}
+	  }
+	}`))
+	assert.Nil(t, err)
+	assert.False(t, result.Failed())
+
+	score, ok := result.Score("performance")
+	assert.True(t, ok)
+	assert.Equal(t, 92, score)
+
+	metrics := result.Metrics()
+	assert.Equal(t, 2345.6, metrics.LargestContentfulPaint)
+	assert.Equal(t, 0.012, metrics.CumulativeLayoutShift)
+	assert.Equal(t, 150.0, metrics.TotalBlockingTime)
+}
+
+func TestParsingFailedLighthouseResult(t *testing.T) {
+	result, err := parseLighthouseResult([]byte(`{
+	  "lighthouseVersion": "6.4.1",
+	  "runtimeError": {"code": "NO_FCP", "message": "The page did not paint any content."},
+	  "categories": {"performance": {"id": "performance", "title": "Performance", "score": null}},
+	  "audits": {}
+	}`))
+	assert.Nil(t, err)
+	assert.True(t, result.Failed())
+	assert.Contains(t, result.RuntimeError.Error(), "NO_FCP")
+
+	_, ok := result.Score("performance")
+	assert.False(t, ok)
+}
+
+func TestGettingLighthouseResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "lighthouse.json", r.URL.Query().Get("file"))
+		switch r.URL.Query().Get("test") {
+		case "170101_OK":
+			fmt.Fprint(w, `{"lighthouseVersion": "6.4.1", "audits": {"speed-index": {"numericValue": 1200}}}`)
+		case "170101_FAILED":
+			fmt.Fprint(w, `{"lighthouseVersion": "6.4.1", "runtimeError": {"code": "NO_FCP", "message": "No paint"}}`)
+		case "170101_EMPTY":
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+
+	result, err := client.GetLighthouseResult("170101_OK")
+	assert.Nil(t, err)
+	assert.Equal(t, 1200.0, result.Metrics().SpeedIndex)
+
+	result, err = client.GetLighthouseResult("170101_FAILED")
+	assert.NotNil(t, result)
+	assert.Equal(t, &LighthouseError{Code: "NO_FCP", Message: "No paint"}, err)
+
+	for _, testID := range []string{"170101_EMPTY", "170101_MISSING"} {
+		result, err = client.GetLighthouseResult(testID)
+		assert.Nil(t, result)
+		lighthouseErr, ok := err.(*LighthouseError)
+		assert.True(t, ok)
+		assert.Equal(t, "NO_RESULT", lighthouseErr.Code)
+	}
+}
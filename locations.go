@@ -41,12 +41,12 @@ type jsonLocation struct {
 	Status     string `json:"status"`     // "OK"
 	Group      string `json:"group"`      // "Mobile Devices"
 
-	Default bool `json:"default"`
+	Default FlexBool `json:"default"`
 
 	RelayServer   string `json:"relayServer"`
 	RelayLocation string `json:"relayLocation"`
 
-	PendingTests map[string]int `json:"PendingTests"`
+	PendingTests map[string]FlexInt `json:"PendingTests"`
 }
 
 type jsonLocations struct {
@@ -98,16 +98,21 @@ func (c *Client) GetLocations() (*Locations, error) {
 			result[l.Group] = make([]Location, 0)
 		}
 
+		pending := make(map[string]int, len(l.PendingTests))
+		for name, count := range l.PendingTests {
+			pending[name] = int(count)
+		}
+
 		result[l.Group] = append(result[l.Group], Location{
 			Label:         l.Label,
 			LabelShort:    l.LabelShort,
 			Location:      l.Location,
 			Browsers:      strings.Split(l.Browsers, ","),
 			Status:        l.Status,
-			Default:       l.Default,
+			Default:       bool(l.Default),
 			RelayServer:   l.RelayServer,
 			RelayLocation: l.RelayLocation,
-			PendingTests:  l.PendingTests,
+			PendingTests:  pending,
 		})
 	}
 
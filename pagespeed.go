@@ -0,0 +1,208 @@
+package webpagetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// getgzip.php?test=<testId>&file=1_pagespeed.txt
+
+/*
+{
+  "score": 85,
+  "rule_results": [
+    {
+      "rule_name": "EnableGzipCompression",
+      "localized_rule_name": "Enable compression",
+      "rule_score": 60,
+      "rule_impact": 4.5,
+      "url_blocks": [
+        {
+          "header": {
+            "format": "Compressing the following resources with gzip could reduce their transfer size by $1 ($2 reduction).",
+            "args": [{"type": "BYTES", "value": "12.3KiB"}, {"type": "PERCENTAGE", "value": "66%"}]
+          },
+          "urls": [
+            {
+              "result": {
+                "format": "Compressing $1 could save $2 ($3 reduction).",
+                "args": [{"type": "URL", "value": "http://example.com/app.js"}, ...]
+              }
+            }
+          ]
+        }
+      ]
+    },
+    ...
+  ]
+}
+*/
+
+type jsonPageSpeedFormat struct {
+	Format string `json:"format"`
+	Args   []struct {
+		Type  string          `json:"type"`
+		Value json.RawMessage `json:"value"`
+	} `json:"args"`
+}
+
+type jsonPageSpeedRule struct {
+	Name      string  `json:"rule_name"`
+	Title     string  `json:"localized_rule_name"`
+	Score     FlexInt `json:"rule_score"`
+	Impact    float64 `json:"rule_impact"`
+	URLBlocks []struct {
+		Header jsonPageSpeedFormat `json:"header"`
+		URLs   []struct {
+			Result  jsonPageSpeedFormat   `json:"result"`
+			Details []jsonPageSpeedFormat `json:"details"`
+		} `json:"urls"`
+	} `json:"url_blocks"`
+}
+
+type jsonPageSpeed struct {
+	Score       FlexInt             `json:"score"`
+	RuleResults []jsonPageSpeedRule `json:"rule_results"`
+}
+
+// PageSpeedFinding is one finding of PageSpeed rule, like
+// "Compressing http://example.com/app.js could save 8.1KiB (66% reduction)."
+type PageSpeedFinding struct {
+	Text    string
+	URL     string
+	Details []string
+}
+
+// PageSpeedBlock is group of findings with common header
+type PageSpeedBlock struct {
+	Header   string
+	Findings []PageSpeedFinding
+}
+
+// PageSpeedRule is result of one PageSpeed rule
+type PageSpeedRule struct {
+	Name   string // "EnableGzipCompression"
+	Title  string // "Enable compression"
+	Score  int    // 0-100
+	Impact float64
+	Blocks []PageSpeedBlock
+}
+
+// URLs returns all URLs behind findings of rule
+func (r PageSpeedRule) URLs() []string {
+	result := make([]string, 0)
+	for _, block := range r.Blocks {
+		for _, finding := range block.Findings {
+			if finding.URL != "" {
+				result = append(result, finding.URL)
+			}
+		}
+	}
+	return result
+}
+
+// PageSpeedResult is result of PageSpeed checks for one run
+type PageSpeedResult struct {
+	Score int
+	Rules []PageSpeedRule
+}
+
+// FailedRules returns rules with score below threshold, most impactful first
+func (r *PageSpeedResult) FailedRules(threshold int) []PageSpeedRule {
+	result := make([]PageSpeedRule, 0)
+	for _, rule := range r.Rules {
+		if rule.Score < threshold {
+			result = append(result, rule)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Impact > result[j].Impact
+	})
+	return result
+}
+
+var pageSpeedPlaceholder = regexp.MustCompile(`\$(\d+)`)
+
+// text formats PageSpeed message by replacing $N placeholders with arguments,
+// it also returns first URL argument, if any
+func (f jsonPageSpeedFormat) text() (string, string) {
+	var firstURL string
+	values := make([]string, len(f.Args))
+	for idx, arg := range f.Args {
+		values[idx] = rawString(arg.Value)
+		if arg.Type == "URL" && firstURL == "" {
+			firstURL = values[idx]
+		}
+	}
+
+	text := pageSpeedPlaceholder.ReplaceAllStringFunc(f.Format, func(placeholder string) string {
+		idx, _ := strconv.Atoi(placeholder[1:])
+		if idx < 1 || idx > len(values) {
+			return placeholder
+		}
+		return values[idx-1]
+	})
+	return text, firstURL
+}
+
+// GetPageSpeedData will retrieve PageSpeed results of given run, view (cached or not) and step.
+// Run and step are 1-based
+func (c *Client) GetPageSpeedData(testID string, run int, cached bool, step int) (*PageSpeedResult, error) {
+	body, err := c.query("/getgzip.php", url.Values{
+		"test": []string{testID},
+		"file": []string{resultFileName(run, cached, step, "pagespeed.txt")},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parsePageSpeed(body)
+}
+
+func parsePageSpeed(body []byte) (*PageSpeedResult, error) {
+	var raw jsonPageSpeed
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse PageSpeed data: %v", err)
+	}
+
+	result := PageSpeedResult{
+		Score: int(raw.Score),
+		Rules: make([]PageSpeedRule, 0, len(raw.RuleResults)),
+	}
+	for _, rawRule := range raw.RuleResults {
+		rule := PageSpeedRule{
+			Name:   rawRule.Name,
+			Title:  rawRule.Title,
+			Score:  int(rawRule.Score),
+			Impact: rawRule.Impact,
+			Blocks: make([]PageSpeedBlock, 0, len(rawRule.URLBlocks)),
+		}
+		if rule.Title == "" {
+			rule.Title = rule.Name
+		}
+
+		for _, rawBlock := range rawRule.URLBlocks {
+			header, _ := rawBlock.Header.text()
+			block := PageSpeedBlock{
+				Header:   header,
+				Findings: make([]PageSpeedFinding, 0, len(rawBlock.URLs)),
+			}
+			for _, rawURL := range rawBlock.URLs {
+				var finding PageSpeedFinding
+				finding.Text, finding.URL = rawURL.Result.text()
+				for _, rawDetail := range rawURL.Details {
+					detail, _ := rawDetail.text()
+					finding.Details = append(finding.Details, detail)
+				}
+				block.Findings = append(block.Findings, finding)
+			}
+			rule.Blocks = append(rule.Blocks, block)
+		}
+		result.Rules = append(result.Rules, rule)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,62 @@
+package webpagetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPageSpeed = `{
+  "score": "85",
+  "rule_results": [
+    {
+      "rule_name": "MinifyCss",
+      "localized_rule_name": "Minify CSS",
+      "rule_score": 98,
+      "rule_impact": 0.5,
+      "url_blocks": []
+    },
+    {
+      "rule_name": "EnableGzipCompression",
+      "localized_rule_name": "Enable compression",
+      "rule_score": 60,
+      "rule_impact": 4.5,
+      "url_blocks": [
+        {
+          "header": {
+            "format": "Compressing the following resources with gzip could reduce their transfer size by $1 ($2 reduction).",
+            "args": [{"type": "BYTES", "value": "12.3KiB"}, {"type": "PERCENTAGE", "value": "66%"}]
+          },
+          "urls": [
+            {
+              "result": {
+                "format": "Compressing $1 could save $2 ($3 reduction).",
+                "args": [
+                  {"type": "URL", "value": "http://example.com/app.js"},
+                  {"type": "BYTES", "value": "8.1KiB"},
+                  {"type": "PERCENTAGE", "value": 66}
+                ]
+              }
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}`
+
+func TestParsingPageSpeed(t *testing.T) {
+	result, err := parsePageSpeed([]byte(testPageSpeed))
+	assert.Nil(t, err)
+	assert.Equal(t, 85, result.Score)
+	assert.Len(t, result.Rules, 2)
+
+	failed := result.FailedRules(90)
+	assert.Len(t, failed, 1)
+	assert.Equal(t, "Enable compression", failed[0].Title)
+	assert.Equal(t, []string{"http://example.com/app.js"}, failed[0].URLs())
+	assert.Equal(t, "Compressing the following resources with gzip could reduce their transfer size by 12.3KiB (66% reduction).",
+		failed[0].Blocks[0].Header)
+	assert.Equal(t, "Compressing http://example.com/app.js could save 8.1KiB (66 reduction).",
+		failed[0].Blocks[0].Findings[0].Text)
+}
@@ -0,0 +1,414 @@
+package webpagetest
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Packet capture is only available when test was run with TestSettings.TCPDump,
+// WebPagetest stores it in "<run>[_Cached][_<step>].cap" in classic libpcap format
+
+// Link-layer header types, that can be found in WebPagetest captures
+const (
+	pcapLinkNull     = 0
+	pcapLinkEthernet = 1
+	pcapLinkRaw      = 101
+	pcapLinkLinuxSLL = 113
+	pcapLinkIPv4     = 228
+	pcapLinkIPv6     = 229
+)
+
+// TCP flags
+const (
+	tcpFlagFIN = 0x01
+	tcpFlagSYN = 0x02
+	tcpFlagRST = 0x04
+	tcpFlagACK = 0x10
+)
+
+// TCPConnection is summary of one TCP connection from packet capture
+type TCPConnection struct {
+	ClientIP   string
+	ClientPort int
+	ServerIP   string
+	ServerPort int
+
+	// Time of first packet of connection
+	Start time.Time
+	// Time between SYN and SYN-ACK, 0 if handshake wasn't captured
+	HandshakeRTT time.Duration
+
+	Packets int
+	// Payload bytes sent by client and by server
+	BytesSent     int64
+	BytesReceived int64
+	// Number of data segments, that were sent again
+	Retransmissions int
+	DataSegments    int
+
+	Reset  bool
+	Closed bool
+
+	// highest sequence number seen in each direction (client->server, server->client)
+	clientSeqEnd, serverSeqEnd uint32
+	clientSeqSet, serverSeqSet bool
+	synTime                    time.Time
+}
+
+// Server returns "ip:port" of server side of connection
+func (c *TCPConnection) Server() string {
+	return net.JoinHostPort(c.ServerIP, strconv.Itoa(c.ServerPort))
+}
+
+// PcapSummary is summary of TCP traffic in packet capture
+type PcapSummary struct {
+	// Number of packets in capture and how many of them are TCP
+	Packets    int
+	TCPPackets int
+	// Time of first and last packet
+	Start time.Time
+	End   time.Time
+
+	Connections []*TCPConnection
+}
+
+// Retransmissions returns total number of retransmitted data segments
+func (s *PcapSummary) Retransmissions() int {
+	var result int
+	for _, conn := range s.Connections {
+		result += conn.Retransmissions
+	}
+	return result
+}
+
+// RetransmissionRate returns share of retransmitted data segments in percents,
+// so it can be compared with Connectivity.PacketLossRate
+func (s *PcapSummary) RetransmissionRate() float64 {
+	var segments, retransmissions int
+	for _, conn := range s.Connections {
+		segments += conn.DataSegments
+		retransmissions += conn.Retransmissions
+	}
+	if segments == 0 {
+		return 0
+	}
+	return float64(retransmissions) / float64(segments) * 100
+}
+
+// BytesPerHost returns bytes received from each server IP
+func (s *PcapSummary) BytesPerHost() map[string]int64 {
+	result := make(map[string]int64)
+	for _, conn := range s.Connections {
+		result[conn.ServerIP] += conn.BytesReceived
+	}
+	return result
+}
+
+// BytesPerHostname is like BytesPerHost, but uses host names from requests of test step to
+// resolve server IPs, unknown IPs are kept as is
+func (s *PcapSummary) BytesPerHostname(requests []Request) map[string]int64 {
+	names := make(map[string]string)
+	for _, request := range requests {
+		if request.IP != "" && request.Host != "" {
+			names[request.IP] = request.Host
+		}
+	}
+
+	result := make(map[string]int64)
+	for ip, bytes := range s.BytesPerHost() {
+		if name, ok := names[ip]; ok {
+			ip = name
+		}
+		result[ip] += bytes
+	}
+	return result
+}
+
+// HandshakeRTTs returns handshake RTT of all connections, where it was captured
+func (s *PcapSummary) HandshakeRTTs() []time.Duration {
+	result := make([]time.Duration, 0, len(s.Connections))
+	for _, conn := range s.Connections {
+		if conn.HandshakeRTT > 0 {
+			result = append(result, conn.HandshakeRTT)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// GetTCPDump will download packet capture of given run, view (cached or not) and step and write it to w.
+// Run and step are 1-based
+func (c *Client) GetTCPDump(w io.Writer, testID string, run int, cached bool, step int) error {
+	body, err := c.download(context.Background(), "/getgzip.php", url.Values{
+		"test": []string{testID},
+		"file": []string{resultFilePrefix(run, cached, step) + ".cap"},
+	})
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	_, err = io.Copy(w, body)
+	return err
+}
+
+// ParsePcap reads packet capture in libpcap format and summarizes TCP connections in it
+func ParsePcap(r io.Reader) (*PcapSummary, error) {
+	header := make([]byte, 24)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("failed to read pcap header: %v", err)
+	}
+
+	var order binary.ByteOrder
+	var nanoseconds bool
+	switch {
+	case binary.LittleEndian.Uint32(header) == 0xa1b2c3d4:
+		order = binary.LittleEndian
+	case binary.BigEndian.Uint32(header) == 0xa1b2c3d4:
+		order = binary.BigEndian
+	case binary.LittleEndian.Uint32(header) == 0xa1b23c4d:
+		order, nanoseconds = binary.LittleEndian, true
+	case binary.BigEndian.Uint32(header) == 0xa1b23c4d:
+		order, nanoseconds = binary.BigEndian, true
+	default:
+		return nil, fmt.Errorf("not a pcap file (magic %x), pcapng is not supported", header[:4])
+	}
+	linkType := order.Uint32(header[20:24]) & 0x0fffffff
+
+	summary := PcapSummary{Connections: make([]*TCPConnection, 0)}
+	connections := make(map[string]*TCPConnection)
+
+	record := make([]byte, 16)
+	for {
+		if _, err := io.ReadFull(r, record); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("failed to read packet #%d header: %v", summary.Packets+1, err)
+		}
+		seconds := int64(order.Uint32(record[0:4]))
+		fraction := int64(order.Uint32(record[4:8]))
+		length := order.Uint32(record[8:12])
+		if length > 256*1024 {
+			return nil, fmt.Errorf("packet #%d is too big: %d bytes", summary.Packets+1, length)
+		}
+		if !nanoseconds {
+			fraction *= 1000
+		}
+		timestamp := time.Unix(seconds, fraction)
+
+		data := make([]byte, length)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, fmt.Errorf("failed to read packet #%d: %v", summary.Packets+1, err)
+		}
+
+		summary.Packets++
+		if summary.Start.IsZero() {
+			summary.Start = timestamp
+		}
+		summary.End = timestamp
+
+		packet, ok := decodeTCPPacket(linkType, data)
+		if !ok {
+			continue
+		}
+		summary.TCPPackets++
+		packet.time = timestamp
+
+		conn, fromClient := lookupConnection(connections, &summary, packet)
+		conn.track(packet, fromClient)
+	}
+
+	return &summary, nil
+}
+
+type tcpPacket struct {
+	srcIP, dstIP     string
+	srcPort, dstPort int
+	seq              uint32
+	flags            byte
+	payload          int
+	time             time.Time
+}
+
+func lookupConnection(connections map[string]*TCPConnection, summary *PcapSummary, packet *tcpPacket) (*TCPConnection, bool) {
+	src := net.JoinHostPort(packet.srcIP, strconv.Itoa(packet.srcPort))
+	dst := net.JoinHostPort(packet.dstIP, strconv.Itoa(packet.dstPort))
+	if conn, ok := connections[src+"-"+dst]; ok {
+		return conn, true
+	}
+	if conn, ok := connections[dst+"-"+src]; ok {
+		return conn, false
+	}
+
+	// New connection: side that sent SYN is client, otherwise guess by well-known port
+	fromClient := true
+	if packet.flags&tcpFlagSYN != 0 {
+		fromClient = packet.flags&tcpFlagACK == 0
+	} else if packet.srcPort < 1024 && packet.dstPort >= 1024 {
+		fromClient = false
+	}
+
+	conn := &TCPConnection{Start: packet.time}
+	if fromClient {
+		conn.ClientIP, conn.ClientPort = packet.srcIP, packet.srcPort
+		conn.ServerIP, conn.ServerPort = packet.dstIP, packet.dstPort
+		connections[src+"-"+dst] = conn
+	} else {
+		conn.ClientIP, conn.ClientPort = packet.dstIP, packet.dstPort
+		conn.ServerIP, conn.ServerPort = packet.srcIP, packet.srcPort
+		connections[dst+"-"+src] = conn
+	}
+	summary.Connections = append(summary.Connections, conn)
+	return conn, fromClient
+}
+
+func (c *TCPConnection) track(packet *tcpPacket, fromClient bool) {
+	c.Packets++
+	if packet.flags&tcpFlagRST != 0 {
+		c.Reset = true
+	}
+	if packet.flags&tcpFlagFIN != 0 {
+		c.Closed = true
+	}
+
+	if packet.flags&tcpFlagSYN != 0 {
+		if fromClient && packet.flags&tcpFlagACK == 0 && c.synTime.IsZero() {
+			c.synTime = packet.time
+		}
+		if !fromClient && packet.flags&tcpFlagACK != 0 && !c.synTime.IsZero() && c.HandshakeRTT == 0 {
+			c.HandshakeRTT = packet.time.Sub(c.synTime)
+		}
+	}
+
+	if packet.payload == 0 {
+		return
+	}
+	seqEnd, seqSet := &c.serverSeqEnd, &c.serverSeqSet
+	if fromClient {
+		c.BytesSent += int64(packet.payload)
+		seqEnd, seqSet = &c.clientSeqEnd, &c.clientSeqSet
+	} else {
+		c.BytesReceived += int64(packet.payload)
+	}
+
+	c.DataSegments++
+	end := packet.seq + uint32(packet.payload)
+	// Segment that doesn't go beyond already seen data is retransmission,
+	// comparison is done with wrap-around of sequence numbers in mind
+	if *seqSet && int32(end-*seqEnd) <= 0 {
+		c.Retransmissions++
+		return
+	}
+	*seqEnd = end
+	*seqSet = true
+}
+
+// decodeTCPPacket extracts TCP header fields from captured frame, second value is false
+// for non-TCP or malformed packets
+func decodeTCPPacket(linkType uint32, data []byte) (*tcpPacket, bool) {
+	var etherType uint16
+	switch linkType {
+	case pcapLinkEthernet:
+		if len(data) < 14 {
+			return nil, false
+		}
+		etherType = binary.BigEndian.Uint16(data[12:14])
+		data = data[14:]
+		// 802.1Q VLAN tags
+		for etherType == 0x8100 || etherType == 0x88a8 {
+			if len(data) < 4 {
+				return nil, false
+			}
+			etherType = binary.BigEndian.Uint16(data[2:4])
+			data = data[4:]
+		}
+	case pcapLinkLinuxSLL:
+		if len(data) < 16 {
+			return nil, false
+		}
+		etherType = binary.BigEndian.Uint16(data[14:16])
+		data = data[16:]
+	case pcapLinkNull:
+		if len(data) < 4 {
+			return nil, false
+		}
+		data = data[4:]
+	case pcapLinkRaw, pcapLinkIPv4, pcapLinkIPv6:
+	default:
+		return nil, false
+	}
+	if len(data) == 0 {
+		return nil, false
+	}
+
+	// Length of TCP segment from IP header, captured data can be cut by snapshot length
+	var tcpLength int
+	var packet tcpPacket
+	version := data[0] >> 4
+	switch {
+	case version == 4 && (etherType == 0 || etherType == 0x0800):
+		if len(data) < 20 {
+			return nil, false
+		}
+		headerLength := int(data[0]&0x0f) * 4
+		totalLength := int(binary.BigEndian.Uint16(data[2:4]))
+		// With TCP segmentation offload total length can be 0, then captured length is all we know
+		if totalLength == 0 {
+			totalLength = len(data)
+		}
+		if data[9] != 6 || headerLength < 20 || totalLength < headerLength || len(data) < headerLength {
+			return nil, false
+		}
+		// Fragments other than first have no TCP header
+		if binary.BigEndian.Uint16(data[6:8])&0x1fff != 0 {
+			return nil, false
+		}
+		packet.srcIP = net.IP(data[12:16]).String()
+		packet.dstIP = net.IP(data[16:20]).String()
+		if totalLength < len(data) {
+			data = data[:totalLength]
+		}
+		data = data[headerLength:]
+		tcpLength = totalLength - headerLength
+	case version == 6 && (etherType == 0 || etherType == 0x86dd):
+		if len(data) < 40 || data[6] != 6 {
+			return nil, false
+		}
+		packet.srcIP = net.IP(data[8:24]).String()
+		packet.dstIP = net.IP(data[24:40]).String()
+		payloadLength := int(binary.BigEndian.Uint16(data[4:6]))
+		data = data[40:]
+		if payloadLength < len(data) {
+			data = data[:payloadLength]
+		}
+		tcpLength = payloadLength
+		if payloadLength == 0 {
+			tcpLength = len(data)
+		}
+	default:
+		return nil, false
+	}
+
+	if len(data) < 20 {
+		return nil, false
+	}
+	offset := int(data[12]>>4) * 4
+	if offset < 20 || offset > len(data) || offset > tcpLength {
+		return nil, false
+	}
+	packet.srcPort = int(binary.BigEndian.Uint16(data[0:2]))
+	packet.dstPort = int(binary.BigEndian.Uint16(data[2:4]))
+	packet.seq = binary.BigEndian.Uint32(data[4:8])
+	packet.flags = data[13]
+	packet.payload = tcpLength - offset
+
+	return &packet, true
+}
@@ -0,0 +1,129 @@
+package webpagetest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testPcap builds libpcap capture of ethernet frames with IPv4/TCP packets
+type testPcap struct {
+	bytes.Buffer
+	// Snapshot length, frames are cut to it if set
+	snaplen int
+	// IP total length is 0, like for segments captured with TCP segmentation offload
+	offload bool
+}
+
+func newTestPcap() *testPcap {
+	p := &testPcap{}
+	binary.Write(p, binary.LittleEndian, []uint32{0xa1b2c3d4, 0x00040002, 0, 0, 65535, pcapLinkEthernet})
+	return p
+}
+
+func (p *testPcap) add(ms int, src, dst []byte, srcPort, dstPort uint16, seq uint32, flags byte, payload int) {
+	tcp := make([]byte, 20+payload)
+	binary.BigEndian.PutUint16(tcp[0:], srcPort)
+	binary.BigEndian.PutUint16(tcp[2:], dstPort)
+	binary.BigEndian.PutUint32(tcp[4:], seq)
+	tcp[12] = 5 << 4
+	tcp[13] = flags
+
+	ip := make([]byte, 20)
+	ip[0] = 0x45
+	if !p.offload {
+		binary.BigEndian.PutUint16(ip[2:], uint16(20+len(tcp)))
+	}
+	ip[9] = 6
+	copy(ip[12:], src)
+	copy(ip[16:], dst)
+
+	frame := append(make([]byte, 12), 0x08, 0x00)
+	frame = append(append(frame, ip...), tcp...)
+
+	length := len(frame)
+	if p.snaplen > 0 && len(frame) > p.snaplen {
+		frame = frame[:p.snaplen]
+	}
+	binary.Write(p, binary.LittleEndian, []uint32{1500000000, uint32(ms * 1000), uint32(len(frame)), uint32(length)})
+	p.Write(frame)
+}
+
+func TestParsingPcap(t *testing.T) {
+	client, server := []byte{10, 0, 0, 2}, []byte{93, 184, 216, 34}
+
+	p := newTestPcap()
+	p.add(0, client, server, 50000, 443, 100, tcpFlagSYN, 0)
+	p.add(30, server, client, 443, 50000, 900, tcpFlagSYN|tcpFlagACK, 0)
+	p.add(31, client, server, 50000, 443, 101, tcpFlagACK, 0)
+	p.add(32, client, server, 50000, 443, 101, tcpFlagACK, 200)
+	p.add(70, server, client, 443, 50000, 901, tcpFlagACK, 1000)
+	p.add(71, server, client, 443, 50000, 1901, tcpFlagACK, 1000)
+	// Retransmission of second segment
+	p.add(300, server, client, 443, 50000, 1901, tcpFlagACK, 1000)
+	p.add(301, server, client, 443, 50000, 2901, tcpFlagACK|tcpFlagFIN, 500)
+
+	summary, err := ParsePcap(&p.Buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, 8, summary.Packets)
+	assert.Equal(t, 8, summary.TCPPackets)
+	assert.Len(t, summary.Connections, 1)
+
+	conn := summary.Connections[0]
+	assert.Equal(t, "10.0.0.2", conn.ClientIP)
+	assert.Equal(t, "93.184.216.34:443", conn.Server())
+	assert.Equal(t, 30*time.Millisecond, conn.HandshakeRTT)
+	assert.Equal(t, int64(200), conn.BytesSent)
+	assert.Equal(t, int64(3500), conn.BytesReceived)
+	assert.Equal(t, 1, conn.Retransmissions)
+	assert.True(t, conn.Closed)
+
+	assert.Equal(t, 1, summary.Retransmissions())
+	assert.Equal(t, 20.0, summary.RetransmissionRate())
+	assert.Equal(t, map[string]int64{"example.com": 3500},
+		summary.BytesPerHostname([]Request{{IP: "93.184.216.34", Host: "example.com"}}))
+}
+
+func TestParsingTruncatedPcap(t *testing.T) {
+	client, server := []byte{10, 0, 0, 2}, []byte{93, 184, 216, 34}
+
+	// Only ethernet, IP and TCP headers are captured
+	p := newTestPcap()
+	p.snaplen = 54
+	p.add(0, client, server, 50000, 443, 100, tcpFlagSYN, 0)
+	p.add(30, server, client, 443, 50000, 900, tcpFlagSYN|tcpFlagACK, 0)
+	p.add(32, client, server, 50000, 443, 101, tcpFlagACK, 200)
+	p.add(70, server, client, 443, 50000, 901, tcpFlagACK, 1400)
+
+	summary, err := ParsePcap(&p.Buffer)
+	assert.Nil(t, err)
+	assert.Len(t, summary.Connections, 1)
+	assert.Equal(t, int64(200), summary.Connections[0].BytesSent)
+	assert.Equal(t, int64(1400), summary.Connections[0].BytesReceived)
+}
+
+func TestParsingOffloadedPcap(t *testing.T) {
+	client, server := []byte{10, 0, 0, 2}, []byte{93, 184, 216, 34}
+
+	p := newTestPcap()
+	p.offload = true
+	p.add(0, client, server, 50000, 443, 100, tcpFlagSYN, 0)
+	p.add(30, server, client, 443, 50000, 900, tcpFlagSYN|tcpFlagACK, 0)
+	p.add(32, client, server, 50000, 443, 101, tcpFlagACK, 200)
+	p.add(70, server, client, 443, 50000, 901, tcpFlagACK, 20000)
+
+	summary, err := ParsePcap(&p.Buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, summary.TCPPackets)
+	assert.Len(t, summary.Connections, 1)
+	assert.Equal(t, int64(200), summary.Connections[0].BytesSent)
+	assert.Equal(t, int64(20000), summary.Connections[0].BytesReceived)
+}
+
+func TestParsingNotPcap(t *testing.T) {
+	_, err := ParsePcap(bytes.NewReader(make([]byte, 24)))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,222 @@
+package webpagetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// testBalance.php?k=<API key>&f=json
+
+/*
+{
+  "statusCode": 200,
+  "statusText": "Ok",
+  "data": {
+    "remaining": 1234
+  }
+}
+*/
+
+// QuotaError is returned when API key has not enough test units left to run tests
+type QuotaError struct {
+	// Test units needed to run tests
+	Required int
+	// Test units left for API key
+	Remaining int
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("not enough test units: %d required, %d remaining", e.Required, e.Remaining)
+}
+
+// QuotaCheckError is returned when test balance of API key could not be checked
+type QuotaCheckError struct {
+	Err error
+}
+
+func (e *QuotaCheckError) Error() string {
+	return fmt.Sprintf("failed to check test balance: %v", e.Err)
+}
+
+// Unwrap returns cause of error
+func (e *QuotaCheckError) Unwrap() error {
+	return e.Err
+}
+
+// GetTestBalance will return how many test units are left for client's API key today
+func (c *Client) GetTestBalance() (int, error) {
+	return c.getTestBalance(c.APIKey)
+}
+
+func (c *Client) getTestBalance(apiKey string) (int, error) {
+	body, err := c.query("/testBalance.php", url.Values{
+		"k": []string{apiKey},
+		"f": []string{"json"},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Some servers answer just with a number
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] != '{' {
+		var remaining FlexInt
+		if err = json.Unmarshal(trimmed, &remaining); err != nil {
+			return 0, err
+		}
+		return int(remaining), nil
+	}
+
+	var result struct {
+		StatusCode int    `json:"statusCode"`
+		StatusText string `json:"statusText"`
+		Data       struct {
+			Remaining FlexInt `json:"remaining"`
+		} `json:"data"`
+	}
+	if err = json.Unmarshal(trimmed, &result); err != nil {
+		return 0, err
+	}
+	if result.StatusCode > 200 {
+		return 0, fmt.Errorf("StatusCode > 200: %v: %v", result.StatusCode, result.StatusText)
+	}
+	return int(result.Data.Remaining), nil
+}
+
+// checkQuota returns *QuotaError if apiKey has less than required test units left
+// and *QuotaCheckError if balance could not be retrieved. Check is skipped for empty key
+func (c *Client) checkQuota(apiKey string, required int) error {
+	if apiKey == "" {
+		return nil
+	}
+	remaining, err := c.getTestBalance(apiKey)
+	if err != nil {
+		return &QuotaCheckError{Err: err}
+	}
+	if remaining < required {
+		return &QuotaError{Required: required, Remaining: remaining}
+	}
+	return nil
+}
+
+// Cost estimates how many test units test will take: runs × views × steps
+func (s TestSettings) Cost() int {
+	runs := s.Runs
+	if runs < 1 {
+		runs = 1
+	}
+	views := 2
+	if s.FirstViewOnly {
+		views = 1
+	}
+	return runs * views * scriptSteps(s.Script)
+}
+
+// scriptSteps estimates number of steps, that script will record, at least 1
+func scriptSteps(script string) int {
+	if steps := ParseScript(script).Steps(); steps > 0 {
+		return steps
+	}
+	return 1
+}
+
+// stepCounter counts steps, that script records, command by command: every navigation
+// with logData on is a step, unless they are combined with combineSteps
+type stepCounter struct {
+	steps   int
+	logging bool
+	// Number of next steps to merge into combined one, -1 for all remaining
+	combineLeft  int
+	combineStart bool
+}
+
+func newStepCounter() *stepCounter {
+	return &stepCounter{logging: true}
+}
+
+// add counts given command and reports if it started new step
+func (c *stepCounter) add(command ScriptCommand) bool {
+	name, args := commandNameArgs(command)
+	switch name {
+	case "logdata":
+		c.logging = len(args) == 0 || strings.TrimSpace(args[0]) != "0"
+		return false
+	case "combinesteps":
+		c.combineStart = true
+		c.combineLeft = -1
+		if len(args) > 0 {
+			if count, _ := strconv.Atoi(strings.TrimSpace(args[0])); count > 0 {
+				c.combineLeft = count
+			}
+		}
+		return false
+	}
+	if !isStepCommand(name) || !c.logging {
+		return false
+	}
+
+	if c.combineLeft != 0 {
+		started := c.combineStart
+		if started {
+			c.steps++
+			c.combineStart = false
+		}
+		if c.combineLeft > 0 {
+			c.combineLeft--
+		}
+		return started
+	}
+	c.steps++
+	return true
+}
+
+// commandNameArgs returns lower case name and arguments of command, arguments
+// separated from name with spaces instead of tab are also split off
+func commandNameArgs(command ScriptCommand) (string, []string) {
+	fields := strings.Fields(command.Name)
+	if len(fields) == 0 {
+		return "", command.Args
+	}
+	return strings.ToLower(fields[0]), append(fields[1:], command.Args...)
+}
+
+// isStepCommand reports if command waits for page to load, so it is recorded as step
+func isStepCommand(name string) bool {
+	name = strings.ToLower(name)
+	return name == "navigate" || name == "waitforcomplete" || strings.HasSuffix(name, "andwait")
+}
+
+// RunTests will submit all given tests. If CheckQuota is set, it will first check that
+// API keys have enough test units left for all of them and will return *QuotaError
+// without submitting anything otherwise
+func (c *Client) RunTests(tests []TestSettings) ([]string, error) {
+	tests = append([]TestSettings(nil), tests...)
+	costs := make(map[string]int)
+	for idx := range tests {
+		if tests[idx].APIKey == "" {
+			tests[idx].APIKey = c.APIKey
+		}
+		costs[tests[idx].APIKey] += tests[idx].Cost()
+	}
+	if c.CheckQuota {
+		for apiKey, cost := range costs {
+			if err := c.checkQuota(apiKey, cost); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result := make([]string, 0, len(tests))
+	for _, settings := range tests {
+		testID, err := c.submitTest(settings)
+		if err != nil {
+			return result, settings.redactError(fmt.Errorf("failed to submit test for %s: %v", settings.URL, err))
+		}
+		result = append(result, testID)
+	}
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package webpagetest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestSettingsCost(t *testing.T) {
+	assert.Equal(t, 2, TestSettings{URL: "http://google.com"}.Cost())
+	assert.Equal(t, 3, TestSettings{URL: "http://google.com", Runs: 3, FirstViewOnly: true}.Cost())
+
+	script := "logData\t0\nnavigate\thttp://example.com/login\nlogData\t1\n" +
+		"setValue\tid=user\tme\nsubmitFormAndWait\n\nclickAndWait\tid=next\n"
+	assert.Equal(t, 12, TestSettings{Runs: 3, Script: script}.Cost())
+	assert.Equal(t, 6, TestSettings{Runs: 3, Script: "combineSteps\n" + script}.Cost())
+}
+
+func TestCountingScriptSteps(t *testing.T) {
+	// Navigations before logData 1 are not recorded
+	assert.Equal(t, 2, scriptSteps("logData\t0\nnavigate\thttp://example.com/login\nlogData\t1\n"+
+		"submitFormAndWait\tid=login\nclickAndWait\tid=next\n"))
+	// First two navigations are combined into one step
+	assert.Equal(t, 2, scriptSteps("combineSteps\t2\nnavigate\thttp://example.com\n"+
+		"clickAndWait\tid=next\nexecAndWait\tdocument.forms[0].submit()\n"))
+	assert.Equal(t, 2, scriptSteps("NAVIGATE\thttp://example.com\nexec\tfoo()\nwaitForComplete\n"))
+	// Script without navigations still runs one step
+	assert.Equal(t, 1, scriptSteps("setCookie\thttp://example.com\tfoo=bar\n"))
+}
+
+func TestQuotaError(t *testing.T) {
+	var err error = &QuotaError{Required: 18, Remaining: 4}
+	assert.Equal(t, "not enough test units: 18 required, 4 remaining", err.Error())
+}
+
+func TestRunningTestsWithQuota(t *testing.T) {
+	submitted := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/testBalance.php":
+			if r.URL.Query().Get("k") == "broken" {
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+			fmt.Fprint(w, `{"statusCode": 200, "statusText": "Ok", "data": {"remaining": 5}}`)
+		case "/runtest.php":
+			submitted++
+			fmt.Fprintf(w, `{"statusCode": 200, "statusText": "Ok", "data": {"testId": "170101_AB_%d"}}`, submitted)
+		}
+	}))
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+	client.APIKey = "key"
+	tests := []TestSettings{{URL: "http://google.com"}, {URL: "http://example.com", Runs: 2}}
+
+	// Quota is not checked by default
+	ids, err := client.RunTests(tests)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"170101_AB_1", "170101_AB_2"}, ids)
+
+	client.CheckQuota = true
+	_, err = client.RunTests(tests)
+	assert.Equal(t, &QuotaError{Required: 6, Remaining: 5}, err)
+	assert.Equal(t, 2, submitted)
+
+	testID, err := client.RunTest(tests[0])
+	assert.Nil(t, err)
+	assert.Equal(t, "170101_AB_3", testID)
+
+	client.APIKey = "broken"
+	_, err = client.RunTest(tests[0])
+	checkErr, ok := err.(*QuotaCheckError)
+	assert.True(t, ok)
+	assert.Contains(t, checkErr.Err.Error(), "500")
+	assert.Equal(t, 3, submitted)
+}
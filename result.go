@@ -1,11 +1,17 @@
 package webpagetest
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"net/url"
+	"reflect"
 	"sort"
 	"strconv"
+	"strings"
+	"sync"
 )
 
 // https://sites.google.com/a/webpagetest.org/docs/advanced-features/raw-test-results
@@ -73,107 +79,294 @@ type Headers struct {
 	Response []string `json:"response"`
 }
 
-type jsonRequest struct {
+// Request is one http request made during test step, it's only available
+// when result was requested with requests=1
+type Request struct {
 	IP           string `json:"ip_addr"`      // "173.194.122.199"
 	Method       string `json:"method"`       // "GET"
 	Host         string `json:"host"`         // "google.com"
 	URL          string `json:"url"`          // "/"
 	FullURL      string `json:"full_url"`     // "http://google.com/"
-	ResponseCode string `json:"responseCode"` // "302",
+	ResponseCode int    `json:"responseCode"` // "302",
 
-	Protocol  string `json:"protocol"`          // "HTTP/2"
-	RequestID int    `json:"request_id,string"` // "9"
-	Index     int    `json:"index"`             // 0
-	Number    int    `json:"number"`            // 1
+	Protocol  string `json:"protocol"`   // "HTTP/2"
+	RequestID int    `json:"request_id"` // "9"
+	Index     int    `json:"index"`      // 0
+	Number    int    `json:"number"`     // 1
 
-	Type     int    `json:"type,string"`   // "3"
-	Socket   int    `json:"socket,string"` // "22"
-	Priority string `json:"priority"`      // "VeryHigh",
+	Type     int    `json:"type"`     // "3"
+	Socket   int    `json:"socket"`   // "22"
+	Priority string `json:"priority"` // "VeryHigh",
 
 	// Network
-	BytesOut         int `json:"bytesOut,string"`          // "397"
-	BytesIn          int `json:"bytesIn,string"`           // "467"
-	ServerCount      int `json:"server_count,string"`      // "11"
-	ServerRTT        int `json:"server_rtt,string"`        // "26"
-	ClientPort       int `json:"client_port,string"`       // "55276"
-	IsSecure         int `json:"is_secure,string"`         // "0"
-	CertificateBytes int `json:"certificate_bytes,string"` // "0", "3769",
+	BytesOut         int  `json:"bytesOut"`          // "397"
+	BytesIn          int  `json:"bytesIn"`           // "467"
+	ServerCount      int  `json:"server_count"`      // "11"
+	ServerRTT        int  `json:"server_rtt"`        // "26"
+	ClientPort       int  `json:"client_port"`       // "55276"
+	IsSecure         bool `json:"is_secure"`         // "0"
+	CertificateBytes int  `json:"certificate_bytes"` // "0", "3769",
 
 	// Cache
-	Expires         string `json:"expires"`           // "Tue, 14 Nov 2017 22:46:51 GMT", "-1"
-	CacheControl    string `json:"cacheControl"`      // "private"
-	CacheTime       int    `json:"cache_time,string"` // "0"
-	ContentType     string `json:"contentType"`       // "text/html"
-	ContentEncoding string `json:"contentEncoding"`   // "gzip"
-	ObjectSize      int    `json:"objectSize,string"` // "256"
-	CDNProvider     string `json:"cdn_provider"`      // "Google",
+	Expires         string `json:"expires"`         // "Tue, 14 Nov 2017 22:46:51 GMT", "-1"
+	CacheControl    string `json:"cacheControl"`    // "private"
+	CacheTime       int    `json:"cache_time"`      // "0"
+	ContentType     string `json:"contentType"`     // "text/html"
+	ContentEncoding string `json:"contentEncoding"` // "gzip"
+	ObjectSize      int    `json:"objectSize"`      // "256"
+	CDNProvider     string `json:"cdn_provider"`    // "Google",
 
 	// Timings
-	DNSStart json.Number `json:"dns_start"` // "0"
-	DNSEnd   json.Number `json:"dns_end"`   // "50"
-	DNS      json.Number `json:"dns_ms"`    // "-1",
+	DNSStart float64 `json:"dns_start"` // "0"
+	DNSEnd   float64 `json:"dns_end"`   // "50"
+	DNS      float64 `json:"dns_ms"`    // "-1",
 
-	ConnectStart json.Number `json:"connect_start"` // "50"
-	ConnectEnd   json.Number `json:"connect_end"`   // "76"
-	Connect      json.Number `json:"connect_ms"`    // 26,
+	ConnectStart float64 `json:"connect_start"` // "50"
+	ConnectEnd   float64 `json:"connect_end"`   // "76"
+	Connect      float64 `json:"connect_ms"`    // 26,
 
-	SSLStart json.Number `json:"ssl_start"` // "0"
-	SSLEnd   json.Number `json:"ssl_end"`   // "0"
-	SSL      json.Number `json:"ssl_ms"`    // "-1",
+	SSLStart float64 `json:"ssl_start"` // "0"
+	SSLEnd   float64 `json:"ssl_end"`   // "0"
+	SSL      float64 `json:"ssl_ms"`    // "-1",
 
-	LoadStart json.Number `json:"load_start,string"` // "76"
-	LoadEnd   json.Number `json:"load_end"`          // 119
-	Load      json.Number `json:"load_ms,string"`    // "43",
+	LoadStart float64 `json:"load_start"` // "76"
+	LoadEnd   float64 `json:"load_end"`   // 119
+	Load      float64 `json:"load_ms"`    // "43",
 
-	TTFBStart json.Number `json:"ttfb_start"`     // "76"
-	TTFBEnd   json.Number `json:"ttfb_end"`       // 119
-	TTFB      json.Number `json:"ttfb_ms,string"` // "43",
+	TTFBStart float64 `json:"ttfb_start"` // "76"
+	TTFBEnd   float64 `json:"ttfb_end"`   // 119
+	TTFB      float64 `json:"ttfb_ms"`    // "43",
 
-	DownloadStart json.Number `json:"download_start"` // 119
-	DownloadEnd   json.Number `json:"download_end"`   // 119
-	Download      json.Number `json:"download_ms"`    // 0,
+	DownloadStart float64 `json:"download_start"` // 119
+	DownloadEnd   float64 `json:"download_end"`   // 119
+	Download      float64 `json:"download_ms"`    // 0,
 
-	AllStart json.Number `json:"all_start"` // "50"
-	AllEnd   json.Number `json:"all_end"`   // 119
-	All      json.Number `json:"all_ms"`    // 69,
+	AllStart float64 `json:"all_start"` // "50"
+	AllEnd   float64 `json:"all_end"`   // 119
+	All      float64 `json:"all_ms"`    // 69,
 
 	// Optimizations
-	ScoreCache           json.Number `json:"score_cache"`            // "0"
-	ScoreCDN             json.Number `json:"score_cdn"`              // "-1"
-	ScoreGZip            json.Number `json:"score_gzip"`             // "-1"
-	ScoreCookies         json.Number `json:"score_cookies"`          // "-1"
-	ScoreKeepAlive       json.Number `json:"score_keep-alive"`       // "-1"
-	ScoreMinify          json.Number `json:"score_minify"`           // "-1"
-	ScoreCombine         json.Number `json:"score_combine"`          // "-1"
-	ScoreCompress        json.Number `json:"score_compress"`         // "-1"
-	ScoreETags           json.Number `json:"score_etags"`            // "-1"
-	ScoreProgressiveJpeg json.Number `json:"score_progressive_jpeg"` // -1
-	GZipTotal            json.Number `json:"gzip_total"`             // "0"
-	GZipSave             json.Number `json:"gzip_save"`              // "0"
-	MinifyTotal          json.Number `json:"minify_total"`           // "0"
-	MinifySave           json.Number `json:"minify_save"`            // "0"
-	ImageTotal           json.Number `json:"image_total"`            // "0"
-	ImageSave            json.Number `json:"image_save"`             // "0"
-	JpegScanCount        json.Number `json:"jpeg_scan_count"`        // "0",
+	ScoreCache           int `json:"score_cache"`            // "0"
+	ScoreCDN             int `json:"score_cdn"`              // "-1"
+	ScoreGZip            int `json:"score_gzip"`             // "-1"
+	ScoreCookies         int `json:"score_cookies"`          // "-1"
+	ScoreKeepAlive       int `json:"score_keep-alive"`       // "-1"
+	ScoreMinify          int `json:"score_minify"`           // "-1"
+	ScoreCombine         int `json:"score_combine"`          // "-1"
+	ScoreCompress        int `json:"score_compress"`         // "-1"
+	ScoreETags           int `json:"score_etags"`            // "-1"
+	ScoreProgressiveJpeg int `json:"score_progressive_jpeg"` // -1
+	GZipTotal            int `json:"gzip_total"`             // "0"
+	GZipSave             int `json:"gzip_save"`              // "0"
+	MinifyTotal          int `json:"minify_total"`           // "0"
+	MinifySave           int `json:"minify_save"`            // "0"
+	ImageTotal           int `json:"image_total"`            // "0"
+	ImageSave            int `json:"image_save"`             // "0"
+	JpegScanCount        int `json:"jpeg_scan_count"`        // "0",
 
 	// HTTP/2
-	HTTP2StreamDependency int `json:"http2_stream_dependency,string"` // "5"
-	HTTP2StreamExclusive  int `json:"http2_stream_exclusive,string"`  // "1"
-	HTTP2StreamID         int `json:"http2_stream_id,string"`         // "1"
-	HTTP2StreamWeight     int `json:"http2_stream_weight,string"`     // "256"
-	WasPushed             int `json:"was_pushed,string"`              // "0",
+	HTTP2StreamDependency int  `json:"http2_stream_dependency"` // "5"
+	HTTP2StreamExclusive  bool `json:"http2_stream_exclusive"`  // "1"
+	HTTP2StreamID         int  `json:"http2_stream_id"`         // "1"
+	HTTP2StreamWeight     int  `json:"http2_stream_weight"`     // "256"
+	WasPushed             bool `json:"was_pushed"`              // "0",
 
 	// Initiator info
-	Initiator         string `json:"initiator"`               // "https://www.google.cz/?gfe_rd=cr&ei=JDc5WJ2sDqSE8QfT-5SgBw&gws_rd=ssl"
-	InitiatorColumn   int    `json:"initiator_column,string"` // "104"
-	InitiatorDetail   string `json:"initiator_detail"`        // "{\"lineNumber\":50,\"type\":\"parser\",\"url\":\"https://www.google.cz/?gfe_rd=cr&ei=JDc5WJ2sDqSE8QfT-5SgBw&gws_rd=ssl\"}"
-	InitiatorFunction string `json:"initiator_function"`      // "Xm"
-	InitiatorLine     int    `json:"initiator_line,string"`   // "50"
-	InitiatorType     string `json:"initiator_type"`          // "other",
+	Initiator         string `json:"initiator"`          // "https://www.google.cz/?gfe_rd=cr&ei=JDc5WJ2sDqSE8QfT-5SgBw&gws_rd=ssl"
+	InitiatorColumn   int    `json:"initiator_column"`   // "104"
+	InitiatorDetail   string `json:"initiator_detail"`   // "{\"lineNumber\":50,\"type\":\"parser\",\"url\":\"https://www.google.cz/?gfe_rd=cr&ei=JDc5WJ2sDqSE8QfT-5SgBw&gws_rd=ssl\"}"
+	InitiatorFunction string `json:"initiator_function"` // "Xm"
+	InitiatorLine     int    `json:"initiator_line"`     // "50"
+	InitiatorType     string `json:"initiator_type"`     // "other",
+
+	Headers Headers `json:"headers"`
+}
+
+type jsonRequest struct {
+	IP           string  `json:"ip_addr"`
+	Method       string  `json:"method"`
+	Host         string  `json:"host"`
+	URL          string  `json:"url"`
+	FullURL      string  `json:"full_url"`
+	ResponseCode FlexInt `json:"responseCode"`
+
+	Protocol  string  `json:"protocol"`
+	RequestID FlexInt `json:"request_id"`
+	Index     int     `json:"index"`
+	Number    int     `json:"number"`
+
+	Type     FlexInt `json:"type"`
+	Socket   FlexInt `json:"socket"`
+	Priority string  `json:"priority"`
+
+	BytesOut         FlexInt  `json:"bytesOut"`
+	BytesIn          FlexInt  `json:"bytesIn"`
+	ServerCount      FlexInt  `json:"server_count"`
+	ServerRTT        FlexInt  `json:"server_rtt"`
+	ClientPort       FlexInt  `json:"client_port"`
+	IsSecure         FlexBool `json:"is_secure"`
+	CertificateBytes FlexInt  `json:"certificate_bytes"`
+
+	Expires         string  `json:"expires"`
+	CacheControl    string  `json:"cacheControl"`
+	CacheTime       FlexInt `json:"cache_time"`
+	ContentType     string  `json:"contentType"`
+	ContentEncoding string  `json:"contentEncoding"`
+	ObjectSize      FlexInt `json:"objectSize"`
+	CDNProvider     string  `json:"cdn_provider"`
+
+	DNSStart FlexFloat `json:"dns_start"`
+	DNSEnd   FlexFloat `json:"dns_end"`
+	DNS      FlexFloat `json:"dns_ms"`
+
+	ConnectStart FlexFloat `json:"connect_start"`
+	ConnectEnd   FlexFloat `json:"connect_end"`
+	Connect      FlexFloat `json:"connect_ms"`
+
+	SSLStart FlexFloat `json:"ssl_start"`
+	SSLEnd   FlexFloat `json:"ssl_end"`
+	SSL      FlexFloat `json:"ssl_ms"`
+
+	LoadStart FlexFloat `json:"load_start"`
+	LoadEnd   FlexFloat `json:"load_end"`
+	Load      FlexFloat `json:"load_ms"`
+
+	TTFBStart FlexFloat `json:"ttfb_start"`
+	TTFBEnd   FlexFloat `json:"ttfb_end"`
+	TTFB      FlexFloat `json:"ttfb_ms"`
+
+	DownloadStart FlexFloat `json:"download_start"`
+	DownloadEnd   FlexFloat `json:"download_end"`
+	Download      FlexFloat `json:"download_ms"`
+
+	AllStart FlexFloat `json:"all_start"`
+	AllEnd   FlexFloat `json:"all_end"`
+	All      FlexFloat `json:"all_ms"`
+
+	ScoreCache           FlexInt `json:"score_cache"`
+	ScoreCDN             FlexInt `json:"score_cdn"`
+	ScoreGZip            FlexInt `json:"score_gzip"`
+	ScoreCookies         FlexInt `json:"score_cookies"`
+	ScoreKeepAlive       FlexInt `json:"score_keep-alive"`
+	ScoreMinify          FlexInt `json:"score_minify"`
+	ScoreCombine         FlexInt `json:"score_combine"`
+	ScoreCompress        FlexInt `json:"score_compress"`
+	ScoreETags           FlexInt `json:"score_etags"`
+	ScoreProgressiveJpeg FlexInt `json:"score_progressive_jpeg"`
+	GZipTotal            FlexInt `json:"gzip_total"`
+	GZipSave             FlexInt `json:"gzip_save"`
+	MinifyTotal          FlexInt `json:"minify_total"`
+	MinifySave           FlexInt `json:"minify_save"`
+	ImageTotal           FlexInt `json:"image_total"`
+	ImageSave            FlexInt `json:"image_save"`
+	JpegScanCount        FlexInt `json:"jpeg_scan_count"`
+
+	HTTP2StreamDependency FlexInt  `json:"http2_stream_dependency"`
+	HTTP2StreamExclusive  FlexBool `json:"http2_stream_exclusive"`
+	HTTP2StreamID         FlexInt  `json:"http2_stream_id"`
+	HTTP2StreamWeight     FlexInt  `json:"http2_stream_weight"`
+	WasPushed             FlexBool `json:"was_pushed"`
+
+	Initiator         string  `json:"initiator"`
+	InitiatorColumn   FlexInt `json:"initiator_column"`
+	InitiatorDetail   string  `json:"initiator_detail"`
+	InitiatorFunction string  `json:"initiator_function"`
+	InitiatorLine     FlexInt `json:"initiator_line"`
+	InitiatorType     string  `json:"initiator_type"`
 
 	Headers Headers `json:"headers"`
 }
 
+// UnmarshalJSON implements custom unmarshaling logic, because WebPagetest
+// returns numbers and flags both as numbers and as strings
+func (r *Request) UnmarshalJSON(b []byte) error {
+	var raw jsonRequest
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*r = Request{
+		IP:                    raw.IP,
+		Method:                raw.Method,
+		Host:                  raw.Host,
+		URL:                   raw.URL,
+		FullURL:               raw.FullURL,
+		ResponseCode:          int(raw.ResponseCode),
+		Protocol:              raw.Protocol,
+		RequestID:             int(raw.RequestID),
+		Index:                 raw.Index,
+		Number:                raw.Number,
+		Type:                  int(raw.Type),
+		Socket:                int(raw.Socket),
+		Priority:              raw.Priority,
+		BytesOut:              int(raw.BytesOut),
+		BytesIn:               int(raw.BytesIn),
+		ServerCount:           int(raw.ServerCount),
+		ServerRTT:             int(raw.ServerRTT),
+		ClientPort:            int(raw.ClientPort),
+		IsSecure:              bool(raw.IsSecure),
+		CertificateBytes:      int(raw.CertificateBytes),
+		Expires:               raw.Expires,
+		CacheControl:          raw.CacheControl,
+		CacheTime:             int(raw.CacheTime),
+		ContentType:           raw.ContentType,
+		ContentEncoding:       raw.ContentEncoding,
+		ObjectSize:            int(raw.ObjectSize),
+		CDNProvider:           raw.CDNProvider,
+		DNSStart:              float64(raw.DNSStart),
+		DNSEnd:                float64(raw.DNSEnd),
+		DNS:                   float64(raw.DNS),
+		ConnectStart:          float64(raw.ConnectStart),
+		ConnectEnd:            float64(raw.ConnectEnd),
+		Connect:               float64(raw.Connect),
+		SSLStart:              float64(raw.SSLStart),
+		SSLEnd:                float64(raw.SSLEnd),
+		SSL:                   float64(raw.SSL),
+		LoadStart:             float64(raw.LoadStart),
+		LoadEnd:               float64(raw.LoadEnd),
+		Load:                  float64(raw.Load),
+		TTFBStart:             float64(raw.TTFBStart),
+		TTFBEnd:               float64(raw.TTFBEnd),
+		TTFB:                  float64(raw.TTFB),
+		DownloadStart:         float64(raw.DownloadStart),
+		DownloadEnd:           float64(raw.DownloadEnd),
+		Download:              float64(raw.Download),
+		AllStart:              float64(raw.AllStart),
+		AllEnd:                float64(raw.AllEnd),
+		All:                   float64(raw.All),
+		ScoreCache:            int(raw.ScoreCache),
+		ScoreCDN:              int(raw.ScoreCDN),
+		ScoreGZip:             int(raw.ScoreGZip),
+		ScoreCookies:          int(raw.ScoreCookies),
+		ScoreKeepAlive:        int(raw.ScoreKeepAlive),
+		ScoreMinify:           int(raw.ScoreMinify),
+		ScoreCombine:          int(raw.ScoreCombine),
+		ScoreCompress:         int(raw.ScoreCompress),
+		ScoreETags:            int(raw.ScoreETags),
+		ScoreProgressiveJpeg:  int(raw.ScoreProgressiveJpeg),
+		GZipTotal:             int(raw.GZipTotal),
+		GZipSave:              int(raw.GZipSave),
+		MinifyTotal:           int(raw.MinifyTotal),
+		MinifySave:            int(raw.MinifySave),
+		ImageTotal:            int(raw.ImageTotal),
+		ImageSave:             int(raw.ImageSave),
+		JpegScanCount:         int(raw.JpegScanCount),
+		HTTP2StreamDependency: int(raw.HTTP2StreamDependency),
+		HTTP2StreamExclusive:  bool(raw.HTTP2StreamExclusive),
+		HTTP2StreamID:         int(raw.HTTP2StreamID),
+		HTTP2StreamWeight:     int(raw.HTTP2StreamWeight),
+		WasPushed:             bool(raw.WasPushed),
+		Initiator:             raw.Initiator,
+		InitiatorColumn:       int(raw.InitiatorColumn),
+		InitiatorDetail:       raw.InitiatorDetail,
+		InitiatorFunction:     raw.InitiatorFunction,
+		InitiatorLine:         int(raw.InitiatorLine),
+		InitiatorType:         raw.InitiatorType,
+		Headers:               raw.Headers,
+	}
+	return nil
+}
+
 // TestView struct tries to combine to kinds of testViews than WebPagetest returns
 // With Steps in case of scripted run and without steps, when we test single url
 // Because Go is strictly typed, we have to "merge" them in one data type
@@ -188,9 +381,10 @@ type TestView struct {
 // nature of test result's json
 func (tv *TestView) UnmarshalJSON(b []byte) error {
 	var tmp struct {
-		Run           int    `json:"run"`
-		Tester        string `json:"tester"`
-		NumberOfSteps int    `json:"numSteps"`
+		Run           int             `json:"run"`
+		Tester        string          `json:"tester"`
+		NumberOfSteps int             `json:"numSteps"`
+		Steps         json.RawMessage `json:"steps"`
 	}
 	if err := json.Unmarshal(b, &tmp); err != nil {
 		return err
@@ -200,16 +394,11 @@ func (tv *TestView) UnmarshalJSON(b []byte) error {
 	tv.Tester = tmp.Tester
 	tv.NumberOfSteps = tmp.NumberOfSteps
 
-	// If we have "steps" array, than Unmarshal as is
-	if tmp.NumberOfSteps > 1 {
-		var steps struct {
-			Steps []TestStep `json:"steps"`
-		}
-		if err := json.Unmarshal(b, &steps); err != nil {
-			return err
-		}
-		tv.Steps = steps.Steps
-		return nil
+	// If we have "steps" array, than Unmarshal as is. Stored results
+	// always have it, even for single step
+	if steps := bytes.TrimSpace(tmp.Steps); len(steps) > 0 && !bytes.Equal(steps, []byte("null")) {
+		tv.Steps = nil
+		return json.Unmarshal(steps, &tv.Steps)
 	}
 
 	// If we have only one "step", then we emulate steps array
@@ -223,6 +412,17 @@ func (tv *TestView) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// MarshalJSON implements json.Marshaler, view is always encoded with "steps" array,
+// so it can be decoded back without loss
+func (tv TestView) MarshalJSON() ([]byte, error) {
+	type testView TestView
+	view := testView(tv)
+	if view.Steps == nil {
+		view.Steps = []TestStep{}
+	}
+	return json.Marshal(view)
+}
+
 // TestStep is struct with information of one particular test "run"
 type TestStep struct {
 	URL    string `json:"URL"`
@@ -308,7 +508,8 @@ type TestStep struct {
 
 	Connections int `json:"connections"`
 
-	// Requests []jsonRequest `json:"requests"`
+	// Number of requests or list of them, if result was requested with requests=1
+	RawRequests json.RawMessage `json:"requests,omitempty"`
 
 	RequestsFull int `json:"requestsFull"`
 	// The number of http(s) requests before the Document Complete time
@@ -359,6 +560,17 @@ type TestStep struct {
 	TimeToInteractive int `json:"TTIMeasurementEnd"` // 11846
 	LastInteractive   int `json:"LastInteractive"`   // 9571
 
+	// Time to First Contentful Paint (ms)
+	FirstContentfulPaint int `json:"firstContentfulPaint"` // 1021
+	// Core Web Vitals: Largest Contentful Paint (ms), Cumulative Layout Shift and Total Blocking Time (ms)
+	LargestContentfulPaint float64 `json:"chromeUserTiming.LargestContentfulPaint"` // 2345
+	CumulativeLayoutShift  float64 `json:"chromeUserTiming.CumulativeLayoutShift"`  // 0.0123
+	TotalBlockingTime      float64 `json:"TotalBlockingTime"`                       // 245
+	// Candidates for Largest Contentful Paint, last "LargestContentfulPaint" event is the final one
+	LargestPaints []LargestPaint `json:"largestPaints"`
+	// All layout shifts, that were counted to Cumulative Layout Shift
+	LayoutShifts []LayoutShift `json:"LayoutShifts"`
+
 	Pages       Pages                `json:"pages"`
 	Thumbnails  Thumbnails           `json:"thumbnails"`
 	Images      Images               `json:"images"`
@@ -366,10 +578,296 @@ type TestStep struct {
 	VideoFrames []VideoFrame         `json:"videoFrames"`
 	Breakdown   map[string]Breakdown `json:"breakdown"`
 
-	RawDomains json.RawMessage   `json:"domains"`
+	RawDomains json.RawMessage   `json:"domains,omitempty"`
 	Domains    map[string]Domain `json:"-"` // may be empty array
 
 	TestTiming map[string]int `json:"testTiming"`
+
+	ConsoleLog ConsoleLog `json:"consoleLog"`
+
+	// User Timing marks by name (ms), like "main searchbar is ready"
+	UserTimes map[string]float64 `json:"userTimes"`
+	// Time of the last User Timing mark (ms)
+	UserTime float64 `json:"userTime"`
+	// User Timing measures by name
+	UserTimingMeasures map[string]UserTimingMeasure `json:"-"`
+
+	// Fields of step, that are not known to this library, like custom metrics
+	// or metrics added by newer WebPagetest versions
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON implements custom unmarshaling logic, that keeps unknown fields in Extra
+func (ts *TestStep) UnmarshalJSON(b []byte) error {
+	type testStep TestStep
+	if err := json.Unmarshal(b, (*testStep)(ts)); err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	// Raw values are compacted, so stored results don't depend on formatting of source
+	ts.RawRequests = compactJSON(ts.RawRequests)
+	ts.RawDomains = compactJSON(ts.RawDomains)
+
+	// "domains" is empty array, when there are no requests
+	if domains := bytes.TrimSpace(ts.RawDomains); len(domains) > 0 && domains[0] == '{' {
+		if err := json.Unmarshal(domains, &ts.Domains); err != nil {
+			return err
+		}
+	}
+
+	// json.Unmarshal matches field names case-insensitively, so must Extra
+	testStepFields()
+	ts.Extra = make(map[string]json.RawMessage)
+	for key, value := range fields {
+		if _, ok := testStepFieldLower[strings.ToLower(key)]; !ok {
+			ts.Extra[key] = compactJSON(value)
+		}
+	}
+
+	// Some servers only report FCP from Chrome's trace
+	if ts.FirstContentfulPaint == 0 {
+		if value, ok := ts.Metric("chromeUserTiming.firstContentfulPaint"); ok {
+			ts.FirstContentfulPaint = int(value)
+		}
+	}
+
+	var measures []UserTimingMeasure
+	if raw, ok := ts.Extra["userTimingMeasures"]; ok && json.Unmarshal(raw, &measures) == nil && len(measures) > 0 {
+		ts.UserTimingMeasures = make(map[string]UserTimingMeasure, len(measures))
+		for _, measure := range measures {
+			ts.UserTimingMeasures[measure.Name] = measure
+		}
+	}
+
+	// Older servers only have flattened "userTime.<name>" keys
+	if len(ts.UserTimes) == 0 {
+		for key := range ts.Extra {
+			if !strings.HasPrefix(key, "userTime.") {
+				continue
+			}
+			if ts.UserTimes == nil {
+				ts.UserTimes = make(map[string]float64)
+			}
+			ts.UserTimes[strings.TrimPrefix(key, "userTime.")], _ = strconv.ParseFloat(rawString(ts.Extra[key]), 64)
+		}
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, fields from Extra are encoded
+// along with known ones, as they were in original json
+func (ts TestStep) MarshalJSON() ([]byte, error) {
+	type testStep TestStep
+	data, err := json.Marshal(testStep(ts))
+	if err != nil || len(ts.Extra) == 0 {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key, value := range ts.Extra {
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// compactJSON removes insignificant whitespace from raw json, invalid json is returned as is
+func compactJSON(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return raw
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return raw
+	}
+	return json.RawMessage(buf.Bytes())
+}
+
+var (
+	testStepFieldsOnce sync.Once
+	testStepFieldIndex map[string]int
+	// Same as testStepFieldIndex, but by lower case json names
+	testStepFieldLower map[string]int
+)
+
+// testStepFields returns json names of TestStep fields with their indexes
+func testStepFields() map[string]int {
+	testStepFieldsOnce.Do(func() {
+		testStepFieldIndex = make(map[string]int)
+		testStepFieldLower = make(map[string]int)
+		stepType := reflect.TypeOf(TestStep{})
+		for idx := 0; idx < stepType.NumField(); idx++ {
+			field := stepType.Field(idx)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if field.PkgPath != "" || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			testStepFieldIndex[name] = idx
+			testStepFieldLower[strings.ToLower(name)] = idx
+		}
+	})
+	return testStepFieldIndex
+}
+
+// Metric returns numeric value of step by its json name, like "SpeedIndex" or "visualComplete85".
+// User Timing marks are available as "userTime.<name>". Fields unknown to this library are looked up
+// in Extra, numbers encoded as strings are accepted. If there is no field with exact name, known
+// fields are matched case-insensitively, so "speedindex" or "loadtime" also work
+func (ts *TestStep) Metric(name string) (float64, bool) {
+	if idx, ok := testStepFields()[name]; ok {
+		return ts.fieldMetric(idx)
+	}
+
+	if strings.HasPrefix(strings.ToLower(name), "usertime.") {
+		value, ok := ts.UserTimes[name[len("usertime."):]]
+		return value, ok
+	}
+
+	if raw, ok := ts.Extra[name]; ok {
+		value, err := strconv.ParseFloat(strings.TrimSpace(rawString(raw)), 64)
+		if err != nil {
+			return 0, false
+		}
+		return value, true
+	}
+
+	if idx, ok := testStepFieldLower[strings.ToLower(name)]; ok {
+		return ts.fieldMetric(idx)
+	}
+	return 0, false
+}
+
+// fieldMetric returns value of numeric field by its index
+func (ts *TestStep) fieldMetric(idx int) (float64, bool) {
+	field := reflect.ValueOf(ts).Elem().Field(idx)
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(field.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return field.Float(), true
+	}
+	return 0, false
+}
+
+// Successful reports if step was completed without errors, WebPagetest
+// result codes 0 and 99999 (content errors) are treated as success
+func (ts *TestStep) Successful() bool {
+	return ts.Result == 0 || ts.Result == 99999
+}
+
+// ExtraString returns unknown field of step as string, numbers and other
+// values are returned as their json
+func (ts *TestStep) ExtraString(name string) (string, bool) {
+	raw, ok := ts.Extra[name]
+	if !ok {
+		return "", false
+	}
+	return rawString(raw), true
+}
+
+// DecodeExtra unmarshals unknown field of step into v
+func (ts *TestStep) DecodeExtra(name string, v interface{}) error {
+	raw, ok := ts.Extra[name]
+	if !ok {
+		return fmt.Errorf("step has no field %s", name)
+	}
+	return json.Unmarshal(raw, v)
+}
+
+/*
+"largestPaints": [
+  {
+    "event": "LargestContentfulPaint",
+    "time": 2345,
+    "size": 48000,
+    "type": "image",
+    "element": {
+      "nodeName": "IMG",
+      "url": "https://example.com/hero.jpg",
+      "outerHTML": "<img src=\"hero.jpg\">"
+    }
+  }
+],
+"LayoutShifts": [
+  {"time": 1200, "score": 0.011, "cumulative_score": 0.011, "shift_window_num": 1}
+]
+*/
+
+// LargestPaint is one candidate for Largest Contentful Paint
+type LargestPaint struct {
+	Event   string     `json:"event"` // "LargestContentfulPaint", "LargestImagePaint", "LargestTextPaint"
+	Time    float64    `json:"time"`
+	Size    float64    `json:"size"`
+	Type    string     `json:"type"` // "image", "text"
+	Element LCPElement `json:"element"`
+}
+
+// LCPElement is element, that was painted as Largest Contentful Paint
+type LCPElement struct {
+	NodeName   string `json:"nodeName"`
+	URL        string `json:"url"`
+	Background string `json:"background-image"`
+	Content    string `json:"content"`
+	OuterHTML  string `json:"outerHTML"`
+}
+
+// LayoutShift is one layout shift, that was counted to Cumulative Layout Shift
+type LayoutShift struct {
+	Time            float64 `json:"time"`
+	Score           float64 `json:"score"`
+	CumulativeScore float64 `json:"cumulative_score"`
+	WindowNumber    int     `json:"shift_window_num"`
+}
+
+// LargestContentfulPaintElement returns final Largest Contentful Paint candidate,
+// nil if server didn't report them
+func (ts *TestStep) LargestContentfulPaintElement() *LargestPaint {
+	var result *LargestPaint
+	for idx := range ts.LargestPaints {
+		paint := &ts.LargestPaints[idx]
+		if paint.Event != "LargestContentfulPaint" {
+			continue
+		}
+		if result == nil || paint.Time >= result.Time {
+			result = paint
+		}
+	}
+	return result
+}
+
+// UserTimingMeasure is User Timing measure (performance.measure)
+type UserTimingMeasure struct {
+	Name      string  `json:"name"`
+	StartTime float64 `json:"startTime"`
+	Duration  float64 `json:"duration"`
+}
+
+// Requests returns list of requests of test step, if it was requested with requests=1,
+// otherwise it will be empty
+func (ts *TestStep) Requests() ([]Request, error) {
+	trimmed := bytes.TrimSpace(ts.RawRequests)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return []Request{}, nil
+	}
+
+	var requests []Request
+	if err := json.Unmarshal(trimmed, &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
 }
 
 // TestRun is a test run info
@@ -378,6 +876,19 @@ type TestRun struct {
 	RepeatView TestView `json:"repeatView"`
 }
 
+// MarshalJSON implements json.Marshaler, views without steps are omitted
+// like WebPagetest does for first view only tests
+func (tr TestRun) MarshalJSON() ([]byte, error) {
+	views := make(map[string]TestView, 2)
+	if len(tr.FirstView.Steps) > 0 {
+		views["firstView"] = tr.FirstView
+	}
+	if len(tr.RepeatView.Steps) > 0 {
+		views["repeatView"] = tr.RepeatView
+	}
+	return json.Marshal(views)
+}
+
 // ResultData holds all info about test
 type ResultData struct {
 	Connectivity
@@ -390,7 +901,7 @@ type ResultData struct {
 	Label    string `json:"label"`
 	From     string `json:"from"`
 
-	Mobile           int    `json:"mobile"`
+	Mobile           bool   `json:"mobile"`
 	Completed        int    `json:"completed"`
 	Tester           string `json:"tester"`
 	TesterDNS        string `json:"testerDNS"`
@@ -399,70 +910,119 @@ type ResultData struct {
 	SuccessfulRVRuns int    `json:"successfulRVRuns"`
 
 	Runs map[string]TestRun `json:"runs"`
+
+	// Lighthouse report, if test was run with lighthouse and server included it
+	RawLighthouse json.RawMessage `json:"lighthouse,omitempty"`
 }
 
-// GetMedianRun will calculate and return median run by given metric and step
-// Step is 0-based
+type jsonResultData struct {
+	Connectivity   string    `json:"connectivity"`
+	BandwidthDown  FlexInt   `json:"bwDown"`
+	BandwidthUp    FlexInt   `json:"bwUp"`
+	Latency        FlexInt   `json:"latency"`
+	PacketLossRate FlexFloat `json:"plr"`
+
+	ID       string `json:"id"`
+	URL      string `json:"url"`
+	Summary  string `json:"summary"`
+	TestURL  string `json:"testUrl"`
+	Location string `json:"location"`
+	Label    string `json:"label"`
+	From     string `json:"from"`
+
+	Mobile           FlexBool `json:"mobile"`
+	Completed        FlexInt  `json:"completed"`
+	Tester           string   `json:"tester"`
+	TesterDNS        string   `json:"testerDNS"`
+	FirstViewOnly    FlexBool `json:"fvonly"`
+	SuccessfulFVRuns FlexInt  `json:"successfulFVRuns"`
+	SuccessfulRVRuns FlexInt  `json:"successfulRVRuns"`
+
+	Runs map[string]TestRun `json:"runs"`
+
+	RawLighthouse json.RawMessage `json:"lighthouse"`
+}
+
+// GetMedianRun returns median run by given metric and step, like WebPagetest does it: runs
+// are sorted by value of metric and lower of two middle runs is taken for even number of runs.
+// Step is 0-based. Metric is any metric, that TestStep.Metric accepts, like "loadTime",
+// "SpeedIndex", "userTime.<name>" or name of custom metric. First and repeat views are selected
+// independently, repeat view is empty for first view only tests. Failed runs are ignored
 func (rd *ResultData) GetMedianRun(step int, metric string) (*TestRun, error) {
+	return rd.GetPercentileRun(step, metric, 50)
+}
+
+// GetPercentileRun returns run, that is at given percentile (0 < percentile <= 100) by value
+// of metric in given step (0-based), using nearest-rank method. It's the same as GetMedianRun
+// for 50th percentile. Failed runs are ignored
+func (rd *ResultData) GetPercentileRun(step int, metric string, percentile float64) (*TestRun, error) {
+	if percentile <= 0 || percentile > 100 {
+		return nil, fmt.Errorf("percentile must be in (0, 100], got %v", percentile)
+	}
+
+	firstView := rd.metricValues(false, step, metric)
+	if len(firstView) == 0 {
+		return nil, fmt.Errorf("no successful runs with metric %s in step %d", metric, step+1)
+	}
+
 	var testRun TestRun
-	var firstViewValues []float64
-	var repeatViewValues []float64
-	firstViewValueMap := make(map[float64]string, 0)
-	repeatViewValueMap := make(map[float64]string, 0)
+	testRun.FirstView = rd.Runs[firstView[percentileRank(len(firstView), percentile)].key].FirstView
+	if repeatView := rd.metricValues(true, step, metric); len(repeatView) > 0 {
+		testRun.RepeatView = rd.Runs[repeatView[percentileRank(len(repeatView), percentile)].key].RepeatView
+	}
+	return &testRun, nil
+}
+
+// runValue is value of metric in one run
+type runValue struct {
+	key   string // key of run in ResultData.Runs
+	run   int
+	value float64
+}
 
-	for idx, run := range rd.Runs {
-		if step >= len(run.FirstView.Steps) || step >= len(run.RepeatView.Steps) {
+// metricValues returns values of metric in given step of all successful runs of first or
+// repeat view, sorted by value and run number
+func (rd *ResultData) metricValues(cached bool, step int, metric string) []runValue {
+	values := make([]runValue, 0, len(rd.Runs))
+	for key, run := range rd.Runs {
+		view := run.FirstView
+		if cached {
+			view = run.RepeatView
+		}
+		if step < 0 || step >= len(view.Steps) || !view.Steps[step].Successful() {
 			continue
 		}
-		var firstViewValue float64
-		var repeatViewValue float64
-		switch metric {
-		case "speedindex":
-			firstViewValue = float64(run.FirstView.Steps[step].SpeedIndex)
-			repeatViewValue = float64(run.RepeatView.Steps[step].SpeedIndex)
-		case "loadtime":
-			firstViewValue = float64(run.FirstView.Steps[step].LoadTime)
-			repeatViewValue = float64(run.RepeatView.Steps[step].LoadTime)
-		case "fullyloaded":
-			firstViewValue = float64(run.FirstView.Steps[step].FullyLoaded)
-			repeatViewValue = float64(run.RepeatView.Steps[step].FullyLoaded)
-		default:
-			return nil, fmt.Errorf("unsupported metric: %s", metric)
+		value, ok := view.Steps[step].Metric(metric)
+		if !ok {
+			continue
 		}
-		firstViewValueMap[firstViewValue] = idx
-		repeatViewValueMap[repeatViewValue] = idx
-		firstViewValues = append(firstViewValues, firstViewValue)
-		repeatViewValues = append(repeatViewValues, repeatViewValue)
-	}
-	sort.Float64s(firstViewValues)
-	sort.Float64s(repeatViewValues)
-
-	var firstRunIdx, repeatRunIdx string
-	if len(firstViewValues) == 1 {
-		firstRunIdx = firstViewValueMap[firstViewValues[0]]
-	} else {
-		idx := len(firstViewValues) / 2
-		if len(firstViewValues)%2 == 0 {
-			idx++
+		number, err := strconv.Atoi(key)
+		if err != nil {
+			number = view.Run
 		}
-		firstRunIdx = firstViewValueMap[firstViewValues[idx]]
+		values = append(values, runValue{key: key, run: number, value: value})
 	}
-	testRun.FirstView = rd.Runs[firstRunIdx].FirstView
 
-	if len(repeatViewValues) == 1 {
-		repeatRunIdx = repeatViewValueMap[repeatViewValues[0]]
-	} else {
-		idx := len(repeatViewValues) / 2
-		if len(repeatViewValues)%2 == 0 {
-			idx++
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].value != values[j].value {
+			return values[i].value < values[j].value
 		}
-		if idx <= len(repeatViewValues) {
-			repeatRunIdx = repeatViewValueMap[repeatViewValues[idx]]
-		}
-	}
-	testRun.RepeatView = rd.Runs[repeatRunIdx].RepeatView
+		return values[i].run < values[j].run
+	})
+	return values
+}
 
-	return &testRun, nil
+// percentileRank returns 0-based index of value at given percentile in sorted
+// values of length count, with nearest-rank method
+func percentileRank(count int, percentile float64) int {
+	rank := int(math.Ceil(percentile / 100 * float64(count)))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > count {
+		rank = count
+	}
+	return rank - 1
 }
 
 // GetTestResult returns result of test with testID
@@ -487,32 +1047,276 @@ func (c *Client) GetTestResult(testID string) (*ResultData, error) {
 }
 
 func parseResultResponse(rawResponse []byte) (*ResultData, error) {
-	var err error
-	var responose struct {
-		StatusCode int             `json:"statusCode"`
-		StatusText string          `json:"statusText"`
-		Data       json.RawMessage `json:"data"`
-	}
+	return ParseResult(bytes.NewReader(rawResponse))
+}
 
-	if err = json.Unmarshal(rawResponse, &responose); err != nil {
+// ParseResult decodes test result from full response of jsonResult.php, its "data" section
+// only or stored result. Result is decoded as it's read, steps one by one, so large responses
+// with requests=1 are never held in memory as a whole
+func ParseResult(r io.Reader) (*ResultData, error) {
+	decoder := &resultDecoder{Decoder: json.NewDecoder(r)}
+	if err := decoder.expectDelim('{'); err != nil {
 		return nil, err
 	}
-	if responose.StatusCode != 200 {
-		return nil, fmt.Errorf("Unexpected status %d: %v",
-			responose.StatusCode, responose.StatusText)
+
+	var result ResultData
+	var statusCode *int
+	var statusText string
+	// Error of "data" section, it's only reported if status is OK
+	var dataErr error
+	// Top-level keys are fields of result, unless it's jsonResult.php response
+	envelope := false
+	fields := make(map[string]json.RawMessage)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := token.(string)
+
+		switch key {
+		case "statusCode":
+			envelope = true
+			var code FlexInt
+			if err = decoder.Decode(&code); err != nil {
+				return nil, fmt.Errorf("failed to decode %q: %v", key, err)
+			}
+			statusCode = new(int)
+			*statusCode = int(code)
+		case "statusText":
+			envelope = true
+			if err = decoder.Decode(&statusText); err != nil {
+				return nil, fmt.Errorf("failed to decode %q: %v", key, err)
+			}
+		case "data":
+			envelope = true
+			if statusCode != nil && *statusCode != 200 {
+				if err = decoder.skipValue(); err != nil {
+					return nil, fmt.Errorf("failed to decode %q: %v", key, err)
+				}
+				break
+			}
+			// Rest of data is skipped on error, so status can still be read after it
+			if dataErr = decoder.decodeResultData(&result); dataErr != nil {
+				if err = decoder.skipTo(1); err != nil {
+					return nil, fmt.Errorf("failed to decode %q: %v", key, dataErr)
+				}
+			}
+		default:
+			if err = decoder.decodeResultField(key, &result, fields); err != nil {
+				return nil, fmt.Errorf("failed to decode %q: %v", key, err)
+			}
+		}
+	}
+	if err := decoder.expectDelim('}'); err != nil {
+		return nil, err
 	}
 
-	var resultData ResultData
-	if err = json.Unmarshal(responose.Data, &resultData); err != nil {
+	if statusCode != nil && *statusCode != 200 {
+		return nil, fmt.Errorf("Unexpected status %d: %v", *statusCode, statusText)
+	}
+	if dataErr != nil {
+		return nil, fmt.Errorf("failed to decode \"data\": %v", dataErr)
+	}
+	if envelope {
+		return &result, nil
+	}
+	if err := setResultFields(&result, fields); err != nil {
 		return nil, err
 	}
+	return &result, nil
+}
+
+// resultDecoder reads test result token by token and keeps track of nesting depth,
+// so after error in some value it can skip to next key of outer object
+type resultDecoder struct {
+	*json.Decoder
+	depth int
+}
+
+// Token returns next json token, see json.Decoder.Token
+func (d *resultDecoder) Token() (json.Token, error) {
+	token, err := d.Decoder.Token()
+	if delim, ok := token.(json.Delim); ok {
+		if delim == '{' || delim == '[' {
+			d.depth++
+		} else {
+			d.depth--
+		}
+	}
+	return token, err
+}
+
+// skipTo reads tokens until decoder is back at given depth
+func (d *resultDecoder) skipTo(depth int) error {
+	for d.depth > depth {
+		if _, err := d.Token(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipValue reads next value without decoding it
+func (d *resultDecoder) skipValue() error {
+	depth := d.depth
+	if _, err := d.Token(); err != nil {
+		return err
+	}
+	return d.skipTo(depth)
+}
+
+func (d *resultDecoder) expectDelim(delim json.Delim) error {
+	token, err := d.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %v, got %v", delim, token)
+	}
+	return nil
+}
+
+// walkObject reads json object and calls fn for each key, fn must read value of the key.
+// null and empty array, that PHP encodes empty objects as, are accepted as empty object
+func (d *resultDecoder) walkObject(fn func(key string) error) error {
+	token, err := d.Token()
+	if err != nil {
+		return err
+	}
+	switch token {
+	case nil:
+		return nil
+	case json.Delim('['):
+		return d.expectDelim(']')
+	case json.Delim('{'):
+	default:
+		return fmt.Errorf("expected object, got %v", token)
+	}
+
+	for d.More() {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := token.(string)
+		if err = fn(key); err != nil {
+			return err
+		}
+	}
+	return d.expectDelim('}')
+}
+
+// decodeResultData decodes "data" section of jsonResult.php response
+func (d *resultDecoder) decodeResultData(result *ResultData) error {
+	fields := make(map[string]json.RawMessage)
+	err := d.walkObject(func(key string) error {
+		return d.decodeResultField(key, result, fields)
+	})
+	if err != nil {
+		return err
+	}
+	return setResultFields(result, fields)
+}
+
+// decodeResultField decodes runs of result step by step, other fields are small,
+// so they are collected to be set with setResultFields
+func (d *resultDecoder) decodeResultField(key string, result *ResultData, fields map[string]json.RawMessage) error {
+	if key != "runs" {
+		var value json.RawMessage
+		if err := d.Decode(&value); err != nil {
+			return err
+		}
+		fields[key] = value
+		return nil
+	}
+
+	result.Runs = make(map[string]TestRun)
+	return d.walkObject(func(number string) error {
+		var run TestRun
+		err := d.walkObject(func(key string) error {
+			switch key {
+			case "firstView":
+				return d.decodeView(&run.FirstView)
+			case "repeatView":
+				return d.decodeView(&run.RepeatView)
+			}
+			var skip json.RawMessage
+			return d.Decode(&skip)
+		})
+		result.Runs[number] = run
+		return err
+	})
+}
+
+// decodeView decodes view of test run, if it has "steps" array, steps are decoded one by one
+func (d *resultDecoder) decodeView(view *TestView) error {
+	var steps []TestStep
+	fields := make(map[string]json.RawMessage)
+	err := d.walkObject(func(key string) error {
+		if key != "steps" {
+			var value json.RawMessage
+			if err := d.Decode(&value); err != nil {
+				return err
+			}
+			fields[key] = value
+			return nil
+		}
+
+		token, err := d.Token()
+		if err != nil || token == nil {
+			return err
+		}
+		if token != json.Delim('[') {
+			return fmt.Errorf("expected steps array, got %v", token)
+		}
+		steps = make([]TestStep, 0)
+		for d.More() {
+			var step TestStep
+			if err = d.Decode(&step); err != nil {
+				return err
+			}
+			steps = append(steps, step)
+		}
+		return d.expectDelim(']')
+	})
+	if err != nil {
+		return err
+	}
 
-	// Unset value is "0"
-	if resultData.Connectivity.RawPacketLossRate == nil || string(*resultData.Connectivity.RawPacketLossRate) == "\"0\"" {
-		resultData.Connectivity.PacketLossRate = 0
-	} else {
-		resultData.Connectivity.PacketLossRate, _ = strconv.Atoi(string(*resultData.Connectivity.RawPacketLossRate))
+	if steps == nil && len(fields) == 0 {
+		return nil
 	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	// View without "steps" is a single step itself. It's only known at the end of view,
+	// so its fields are buffered, but it's still no more than one step in memory
+	if steps == nil {
+		var step TestStep
+		if err = json.Unmarshal(data, &step); err != nil {
+			return err
+		}
+		*view = TestView{Run: step.Run, Tester: step.Tester, NumberOfSteps: 1, Steps: []TestStep{step}}
+		return nil
+	}
+	type testView TestView
+	if err = json.Unmarshal(data, (*testView)(view)); err != nil {
+		return err
+	}
+	view.Steps = steps
+	return nil
+}
 
-	return &resultData, nil
+// setResultFields sets fields of result, that were collected while runs were decoded
+func setResultFields(result *ResultData, fields map[string]json.RawMessage) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
 }
@@ -1,8 +1,15 @@
 package webpagetest
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
 	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -10,13 +17,308 @@ import (
 func TestParsingResultWithPlrAsNumber(t *testing.T) {
 	var response, err = ioutil.ReadFile("./testdata/TestResultPlrAsNumber.json")
 	assert.Nil(t, err)
-	_, err = parseResultResponse(response)
+	result, err := parseResultResponse(response)
 	assert.Nil(t, err)
+	assert.Equal(t, 0.0, result.PacketLossRate)
+	assert.Equal(t, 780, result.BandwidthDown)
+	assert.False(t, result.Mobile)
 }
 
 func TestParsingResultWithPlrAsString(t *testing.T) {
 	var response, err = ioutil.ReadFile("./testdata/TestResultPlrAsString.json")
 	assert.Nil(t, err)
-	_, err = parseResultResponse(response)
+	result, err := parseResultResponse(response)
 	assert.Nil(t, err)
+	assert.Equal(t, 0.0, result.PacketLossRate)
+	assert.True(t, result.Mobile)
+}
+
+func TestParsingResultWithFractionalPlr(t *testing.T) {
+	result, err := parseResultResponse([]byte(`{"statusCode": 200, "data": {
+		"connectivity": "custom", "bwDown": 1000, "bwUp": "500", "latency": 40, "plr": "0.5"}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, 0.5, result.PacketLossRate)
+	assert.Equal(t, "custom (1000Kbps/500Kbps) 40ms, Packet Loss 0.5%", result.Connectivity.String())
+}
+
+func TestParsingResultConsoleLog(t *testing.T) {
+	var response, err = ioutil.ReadFile("./testdata/TestResultPlrAsNumber.json")
+	assert.Nil(t, err)
+	result, err := parseResultResponse(response)
+	assert.Nil(t, err)
+
+	consoleLog := result.Runs["1"].FirstView.Steps[0].ConsoleLog
+	assert.Len(t, consoleLog, 4)
+	assert.Equal(t, "console-api", consoleLog[0].Source)
+	assert.Len(t, consoleLog.ByLevel("warning"), 1)
+	assert.Len(t, consoleLog.Errors(), 0)
+
+	doubled := append(consoleLog, consoleLog...)
+	assert.Equal(t, consoleLog, doubled.Unique())
+}
+
+func TestParsingResultUserTimes(t *testing.T) {
+	var response, err = ioutil.ReadFile("./testdata/TestResultPlrAsString.json")
+	assert.Nil(t, err)
+	result, err := parseResultResponse(response)
+	assert.Nil(t, err)
+
+	step := result.Runs["1"].FirstView.Steps[0]
+	assert.Equal(t, map[string]float64{
+		"main searchbar is ready":                   1860,
+		"page content ready":                        1860,
+		"content component MobileMainPage is ready": 1860,
+	}, step.UserTimes)
+	assert.Equal(t, 1860.0, step.UserTime)
+
+	medianRun, err := result.GetMedianRun(0, "userTime.main searchbar is ready")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, medianRun.FirstView.Steps)
+
+	_, err = result.GetMedianRun(0, "userTime.no such mark")
+	assert.NotNil(t, err)
+}
+
+func TestParsingStepUserTimingFallback(t *testing.T) {
+	var step TestStep
+	assert.Nil(t, json.Unmarshal([]byte(`{
+		"userTime.ready": "1234.5",
+		"userTimingMeasures": [{"name": "hydrate", "startTime": 100, "duration": 250.5}]
+	}`), &step))
+	assert.Equal(t, map[string]float64{"ready": 1234.5}, step.UserTimes)
+	assert.Equal(t, 250.5, step.UserTimingMeasures["hydrate"].Duration)
+}
+
+func TestParsingStepWebVitals(t *testing.T) {
+	var step TestStep
+	assert.Nil(t, json.Unmarshal([]byte(`{
+		"chromeUserTiming.firstContentfulPaint": 1021,
+		"chromeUserTiming.LargestContentfulPaint": 2345,
+		"chromeUserTiming.CumulativeLayoutShift": 0.0123,
+		"TotalBlockingTime": 245,
+		"largestPaints": [
+			{"event": "LargestTextPaint", "time": 1100, "size": 2000, "type": "text", "element": {"nodeName": "H1"}},
+			{"event": "LargestContentfulPaint", "time": 1100, "size": 2000, "type": "text", "element": {"nodeName": "H1"}},
+			{"event": "LargestContentfulPaint", "time": 2345, "size": 48000, "type": "image",
+			 "element": {"nodeName": "IMG", "url": "https://example.com/hero.jpg"}}
+		],
+		"LayoutShifts": [{"time": 1200, "score": 0.0123, "cumulative_score": 0.0123, "shift_window_num": 1}]
+	}`), &step))
+
+	assert.Equal(t, 1021, step.FirstContentfulPaint)
+	assert.Equal(t, 2345.0, step.LargestContentfulPaint)
+	assert.Equal(t, 0.0123, step.CumulativeLayoutShift)
+	assert.Equal(t, 245.0, step.TotalBlockingTime)
+	assert.Equal(t, "https://example.com/hero.jpg", step.LargestContentfulPaintElement().Element.URL)
+	assert.Len(t, step.LayoutShifts, 1)
+
+	// Current testdata has no Web Vitals
+	var response, err = ioutil.ReadFile("./testdata/TestResultPlrAsNumber.json")
+	assert.Nil(t, err)
+	result, err := parseResultResponse(response)
+	assert.Nil(t, err)
+	assert.Nil(t, result.Runs["1"].FirstView.Steps[0].LargestContentfulPaintElement())
+	assert.Equal(t, 0.0, result.Runs["1"].FirstView.Steps[0].CumulativeLayoutShift)
+}
+
+func TestParsingStepExtraFields(t *testing.T) {
+	var response, err = ioutil.ReadFile("./testdata/TestResultPlrAsNumber.json")
+	assert.Nil(t, err)
+	result, err := parseResultResponse(response)
+	assert.Nil(t, err)
+
+	step := result.Runs["1"].FirstView.Steps[0]
+	assert.NotContains(t, step.Extra, "loadTime")
+	assert.Contains(t, step.Extra, "visualComplete85")
+
+	value, ok := step.Metric("visualComplete85")
+	assert.True(t, ok)
+	assert.Equal(t, 12200.0, value)
+
+	value, ok = step.Metric("loadTime")
+	assert.True(t, ok)
+	assert.Equal(t, 43226.0, value)
+
+	value, ok = step.Metric("isResponsive")
+	assert.True(t, ok)
+	assert.Equal(t, -1.0, value)
+
+	_, ok = step.Metric("noSuchMetric")
+	assert.False(t, ok)
+
+	var step2 TestStep
+	assert.Nil(t, json.Unmarshal([]byte(`{"loadTime": 1000, "newMetric": "42.5", "newObject": {"a": [1, 2]}, "newName": "hello"}`), &step2))
+	value, ok = step2.Metric("newMetric")
+	assert.True(t, ok)
+	assert.Equal(t, 42.5, value)
+	_, ok = step2.Metric("newName")
+	assert.False(t, ok)
+
+	text, ok := step2.ExtraString("newName")
+	assert.True(t, ok)
+	assert.Equal(t, "hello", text)
+
+	var object struct {
+		A []int `json:"a"`
+	}
+	assert.Nil(t, step2.DecodeExtra("newObject", &object))
+	assert.Equal(t, []int{1, 2}, object.A)
+	assert.NotNil(t, step2.DecodeExtra("missing", &object))
+
+	// Known fields in other case are decoded by json, so they are not extra
+	var step3 TestStep
+	assert.Nil(t, json.Unmarshal([]byte(`{"LoadTime": 1000, "speedindex": 900}`), &step3))
+	assert.Equal(t, 1000, step3.LoadTime)
+	assert.Empty(t, step3.Extra)
+}
+
+func TestParseResult(t *testing.T) {
+	file, err := os.Open("./testdata/TestResultPlrAsString.json")
+	assert.Nil(t, err)
+	defer file.Close()
+
+	result, err := ParseResult(file)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, result.ID)
+	assert.NotEmpty(t, result.Runs)
+
+	// Only "data" section
+	response, err := ioutil.ReadFile("./testdata/TestResultPlrAsString.json")
+	assert.Nil(t, err)
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	assert.Nil(t, json.Unmarshal(response, &envelope))
+	data, err := ParseResult(bytes.NewReader(envelope.Data))
+	assert.Nil(t, err)
+	assert.Equal(t, result, data)
+
+	// Status can be after data
+	result, err = ParseResult(bytes.NewBufferString(`{"data": {"id": "test", "runs": {}}, "statusCode": 200}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "test", result.ID)
+
+	_, err = ParseResult(bytes.NewBufferString(`{"statusCode": 400, "statusText": "Invalid test ID", "data": "oops"}`))
+	assert.EqualError(t, err, "Unexpected status 400: Invalid test ID")
+
+	_, err = ParseResult(bytes.NewBufferString(`[]`))
+	assert.NotNil(t, err)
+	_, err = ParseResult(bytes.NewBufferString(`{"id": "test", "runs": {`))
+	assert.NotNil(t, err)
+
+	// Data of failed test can be anything, status after it is still reported
+	_, err = ParseResult(bytes.NewBufferString(`{"data": {"id": "test", "runs": {"1": {"firstView": "oops", "repeatView": {}}}},
+		"statusCode": 400, "statusText": "Invalid test ID"}`))
+	assert.EqualError(t, err, "Unexpected status 400: Invalid test ID")
+	_, err = ParseResult(bytes.NewBufferString(`{"data": {"id": "test", "runs": {"1": {"firstView": "oops"}}}, "statusCode": 200}`))
+	assert.EqualError(t, err, `failed to decode "data": expected object, got oops`)
+
+	// Fields of response envelope are not fields of result
+	result, err = ParseResult(bytes.NewBufferString(`{"statusCode": 200, "id": "envelope", "data": {"id": "test"}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "test", result.ID)
+
+	// PHP encodes empty objects as empty arrays
+	result, err = ParseResult(bytes.NewBufferString(`{"id": "test", "runs": {"1": {"firstView": {"steps": [{"loadTime": 100}]}, "repeatView": []}}}`))
+	assert.Nil(t, err)
+	assert.Equal(t, 100, result.Runs["1"].FirstView.Steps[0].LoadTime)
+	assert.Empty(t, result.Runs["1"].RepeatView.Steps)
+	result, err = ParseResult(bytes.NewBufferString(`{"id": "test", "runs": []}`))
+	assert.Nil(t, err)
+	assert.Empty(t, result.Runs)
+}
+
+func TestParseResultIsStreaming(t *testing.T) {
+	// Response never ends correctly, so if it was read as a whole before decoding, only
+	// error of reader would be returned. Steps are decoded as they are read, so invalid
+	// step is found first
+	readers := []io.Reader{strings.NewReader(`{"statusCode": 200, "data": {"id": "test", "runs": {"1": {"firstView": {"steps": [`)}
+	for idx := 0; idx < 100; idx++ {
+		step := `{"loadTime": 1000, "SpeedIndex": 900},`
+		if idx == 10 {
+			step = `{"loadTime": "slow"},`
+		}
+		readers = append(readers, strings.NewReader(step))
+	}
+	readers = append(readers, iotest.TimeoutReader(strings.NewReader(`{"loadTime": 1000}`)))
+
+	_, err := ParseResult(io.MultiReader(readers...))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), `failed to decode "data": json: cannot unmarshal string`)
+}
+
+// testResult builds result with first view load times and optional repeat view load times,
+// runs are numbered from 1
+func testResult(firstView []int, repeatView []int) *ResultData {
+	result := &ResultData{Runs: make(map[string]TestRun)}
+	for idx, loadTime := range firstView {
+		run := TestRun{FirstView: TestView{Run: idx + 1, NumberOfSteps: 1, Steps: []TestStep{{Run: idx + 1, LoadTime: loadTime}}}}
+		if idx < len(repeatView) {
+			run.RepeatView = TestView{Run: idx + 1, NumberOfSteps: 1, Steps: []TestStep{{Run: idx + 1, LoadTime: repeatView[idx]}}}
+		}
+		result.Runs[strconv.Itoa(idx+1)] = run
+	}
+	return result
+}
+
+func TestGetMedianRun(t *testing.T) {
+	// Odd number of runs
+	median, err := testResult([]int{300, 100, 200}, []int{30, 10, 20}).GetMedianRun(0, "loadTime")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, median.FirstView.Run)
+	assert.Equal(t, 3, median.RepeatView.Run)
+
+	// Even number of runs: lower middle, like WebPagetest
+	median, err = testResult([]int{200, 100}, []int{10, 20}).GetMedianRun(0, "loadtime")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, median.FirstView.Run)
+	assert.Equal(t, 1, median.RepeatView.Run)
+
+	median, err = testResult([]int{400, 100, 300, 200}, nil).GetMedianRun(0, "loadTime")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, median.FirstView.Run)
+	// First view only test
+	assert.Empty(t, median.RepeatView.Steps)
+
+	// Ties are resolved by run number
+	median, err = testResult([]int{100, 100, 100}, nil).GetMedianRun(0, "loadTime")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, median.FirstView.Run)
+
+	// Failed runs are ignored, content errors are not
+	result := testResult([]int{100, 50, 300, 200}, nil)
+	result.Runs["2"].FirstView.Steps[0].Result = 404
+	result.Runs["4"].FirstView.Steps[0].Result = 99999
+	median, err = result.GetMedianRun(0, "loadTime")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, median.FirstView.Run)
+
+	_, err = result.GetMedianRun(1, "loadTime")
+	assert.NotNil(t, err)
+	_, err = result.GetMedianRun(0, "noSuchMetric")
+	assert.NotNil(t, err)
+}
+
+func TestGetMedianRunByExtraMetric(t *testing.T) {
+	result := testResult([]int{100, 200, 300}, nil)
+	for key, value := range map[string]string{"1": `"30"`, "2": `10`, "3": `20`} {
+		result.Runs[key].FirstView.Steps[0].Extra = map[string]json.RawMessage{"iframe-count": json.RawMessage(value)}
+	}
+	median, err := result.GetMedianRun(0, "iframe-count")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, median.FirstView.Run)
+}
+
+func TestGetPercentileRun(t *testing.T) {
+	result := testResult([]int{100, 200, 300, 400, 500, 600, 700, 800, 900, 1000}, nil)
+	for percentile, run := range map[float64]int{1: 1, 10: 1, 50: 5, 75: 8, 90: 9, 95: 10, 100: 10} {
+		selected, err := result.GetPercentileRun(0, "loadTime", percentile)
+		assert.Nil(t, err)
+		assert.Equal(t, run, selected.FirstView.Run, "p%v", percentile)
+	}
+
+	_, err := result.GetPercentileRun(0, "loadTime", 0)
+	assert.NotNil(t, err)
+	_, err = result.GetPercentileRun(0, "loadTime", 101)
+	assert.NotNil(t, err)
 }
@@ -0,0 +1,202 @@
+package webpagetest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// https://sites.google.com/a/webpagetest.org/docs/using-webpagetest/scripting
+
+/*
+logData	0
+navigate	http://www.example.com/login
+logData	1
+setValue	name=username	user
+setValue	name=password	secret
+submitFormAndWait	name=loginForm
+*/
+
+// ScriptCommand is one command of WebPagetest script, like "navigate" with its arguments
+type ScriptCommand struct {
+	Name string
+	Args []string
+	// Line number in script text, if command was parsed (1-based)
+	Line int
+}
+
+// String renders command as line of script, command and arguments are tab-separated
+func (c ScriptCommand) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), "\t")
+}
+
+// Validate checks that command can be rendered as one valid line of script
+func (c ScriptCommand) Validate() error {
+	if c.Name == "" || strings.ContainsAny(c.Name, " \t\r\n") {
+		return fmt.Errorf("invalid command name %q", c.Name)
+	}
+	for idx, arg := range c.Args {
+		if strings.ContainsAny(arg, "\t\r\n") {
+			return fmt.Errorf("%s: argument #%d contains tab or line break: %q", c.Name, idx+1, arg)
+		}
+	}
+	return nil
+}
+
+// Script is WebPagetest script, it can be built with chained calls:
+//
+//	script := new(webpagetest.Script).
+//		LogData(false).
+//		Navigate("http://www.example.com/login").
+//		LogData(true).
+//		SetValue("name=username", "user").
+//		ClickAndWait("id=submit")
+type Script []ScriptCommand
+
+// Add appends command with given name and arguments, it can be used for commands
+// that don't have their own method
+func (s *Script) Add(name string, args ...string) *Script {
+	*s = append(*s, ScriptCommand{Name: name, Args: args})
+	return s
+}
+
+// Navigate to given URL and wait for page to load
+func (s *Script) Navigate(url string) *Script {
+	return s.Add("navigate", url)
+}
+
+// SetValue sets value attribute of element, target is like "id=login" or "name=user"
+func (s *Script) SetValue(target, value string) *Script {
+	return s.Add("setValue", target, value)
+}
+
+// SetInnerText sets innerText of element
+func (s *Script) SetInnerText(target, text string) *Script {
+	return s.Add("setInnerText", target, text)
+}
+
+// SetInnerHTML sets innerHTML of element
+func (s *Script) SetInnerHTML(target, html string) *Script {
+	return s.Add("setInnerHTML", target, html)
+}
+
+// Click on element without waiting for page activity
+func (s *Script) Click(target string) *Script {
+	return s.Add("click", target)
+}
+
+// ClickAndWait clicks on element and waits for page activity to finish, it's a new step
+func (s *Script) ClickAndWait(target string) *Script {
+	return s.Add("clickAndWait", target)
+}
+
+// SubmitForm submits form without waiting for page activity
+func (s *Script) SubmitForm(target string) *Script {
+	return s.Add("submitForm", target)
+}
+
+// SubmitFormAndWait submits form and waits for page activity to finish, it's a new step
+func (s *Script) SubmitFormAndWait(target string) *Script {
+	return s.Add("submitFormAndWait", target)
+}
+
+// Exec executes javascript without waiting for page activity
+func (s *Script) Exec(script string) *Script {
+	return s.Add("exec", script)
+}
+
+// ExecAndWait executes javascript and waits for page activity to finish, it's a new step
+func (s *Script) ExecAndWait(script string) *Script {
+	return s.Add("execAndWait", script)
+}
+
+// SetCookie sets cookie for given path (URL), cookie is like "name=value; expires=..."
+func (s *Script) SetCookie(path, cookie string) *Script {
+	return s.Add("setCookie", path, cookie)
+}
+
+// SetDNS overrides DNS resolution of host to given IP
+func (s *Script) SetDNS(host, ip string) *Script {
+	return s.Add("setDns", host, ip)
+}
+
+// SetDNSName adds DNS alias (CNAME) of host
+func (s *Script) SetDNSName(host, alias string) *Script {
+	return s.Add("setDNSName", host, alias)
+}
+
+// SetHeader adds custom header to all requests, header is like "Name: value"
+func (s *Script) SetHeader(header string) *Script {
+	return s.Add("setHeader", header)
+}
+
+// Block requests with URLs that contain any of given substrings
+func (s *Script) Block(substrings ...string) *Script {
+	return s.Add("block", strings.Join(substrings, " "))
+}
+
+// BlockDomains blocks all requests to given domains
+func (s *Script) BlockDomains(domains ...string) *Script {
+	return s.Add("blockDomains", strings.Join(domains, " "))
+}
+
+// LogData turns logging of results on or off, steps with logging off are not recorded
+func (s *Script) LogData(enabled bool) *Script {
+	if enabled {
+		return s.Add("logData", "1")
+	}
+	return s.Add("logData", "0")
+}
+
+// SetEventName sets name of next step
+func (s *Script) SetEventName(name string) *Script {
+	return s.Add("setEventName", name)
+}
+
+// CombineSteps combines next count steps into one, 0 means all remaining steps
+func (s *Script) CombineSteps(count int) *Script {
+	if count > 0 {
+		return s.Add("combineSteps", fmt.Sprintf("%d", count))
+	}
+	return s.Add("combineSteps")
+}
+
+// Sleep pauses script for given number of seconds
+func (s *Script) Sleep(seconds int) *Script {
+	return s.Add("sleep", fmt.Sprintf("%d", seconds))
+}
+
+// SetViewportSize changes size of viewport
+func (s *Script) SetViewportSize(width, height int) *Script {
+	return s.Add("setViewportSize", fmt.Sprintf("%d", width), fmt.Sprintf("%d", height))
+}
+
+// Validate checks all commands of script
+func (s Script) Validate() error {
+	if len(s) == 0 {
+		return fmt.Errorf("script is empty")
+	}
+	for idx, command := range s {
+		if err := command.Validate(); err != nil {
+			return fmt.Errorf("command #%d: %v", idx+1, err)
+		}
+	}
+	return nil
+}
+
+// String renders script as text, that WebPagetest expects
+func (s Script) String() string {
+	lines := make([]string, 0, len(s))
+	for _, command := range s {
+		lines = append(lines, command.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetScript validates given script and sets it as scripted test to execute
+func (s *TestSettings) SetScript(script Script) error {
+	if err := script.Validate(); err != nil {
+		return err
+	}
+	s.Script = script.String()
+	return nil
+}
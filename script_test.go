@@ -0,0 +1,42 @@
+package webpagetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildingScript(t *testing.T) {
+	script := new(Script).
+		LogData(false).
+		SetDNS("www.example.com", "127.0.0.1").
+		Navigate("http://www.example.com/login").
+		LogData(true).
+		SetEventName("login").
+		SetValue("name=username", "user").
+		ClickAndWait("id=submit").
+		Block("ads.js", "analytics")
+
+	expected := "logData\t0\n" +
+		"setDns\twww.example.com\t127.0.0.1\n" +
+		"navigate\thttp://www.example.com/login\n" +
+		"logData\t1\n" +
+		"setEventName\tlogin\n" +
+		"setValue\tname=username\tuser\n" +
+		"clickAndWait\tid=submit\n" +
+		"block\tads.js analytics"
+	assert.Equal(t, expected, script.String())
+
+	var settings TestSettings
+	assert.Nil(t, settings.SetScript(*script))
+	assert.Equal(t, expected, settings.Script)
+	assert.Equal(t, 2, settings.Cost())
+}
+
+func TestValidatingScript(t *testing.T) {
+	var settings TestSettings
+	assert.NotNil(t, settings.SetScript(Script{}))
+	assert.NotNil(t, settings.SetScript(*new(Script).ExecAndWait("a = 1;\nb = 2;")))
+	assert.NotNil(t, settings.SetScript(*new(Script).Add("set value", "x")))
+	assert.Equal(t, "", settings.Script)
+}
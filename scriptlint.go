@@ -0,0 +1,214 @@
+package webpagetest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// scriptCommandSpec describes known script command and how many arguments it takes
+type scriptCommandSpec struct {
+	Name    string
+	MinArgs int
+	MaxArgs int
+}
+
+var scriptCommands = map[string]scriptCommandSpec{}
+
+func init() {
+	for _, spec := range []scriptCommandSpec{
+		// Navigation
+		{"navigate", 1, 1},
+		{"click", 1, 1},
+		{"clickAndWait", 1, 1},
+		{"sendClick", 1, 1},
+		{"sendClickAndWait", 1, 1},
+		{"submitForm", 1, 1},
+		{"submitFormAndWait", 1, 1},
+		{"exec", 1, 1},
+		{"execAndWait", 1, 1},
+		{"sendKeyDown", 1, 1},
+		{"sendKeyDownAndWait", 1, 1},
+		{"sendKeyUp", 1, 1},
+		{"sendKeyUpAndWait", 1, 1},
+		{"sendKeyPress", 1, 1},
+		{"sendKeyPressAndWait", 1, 1},
+		{"selectValue", 2, 2},
+		{"setValue", 2, 2},
+		{"setInnerText", 2, 2},
+		{"setInnerHTML", 2, 2},
+		{"waitForComplete", 0, 0},
+		{"waitForJSDone", 0, 0},
+		// Request manipulation
+		{"block", 1, 1},
+		{"blockDomains", 1, 1},
+		{"blockDomainsExcept", 1, 1},
+		{"setCookie", 2, 2},
+		{"setDns", 2, 2},
+		{"setDNSName", 2, 2},
+		{"overrideHost", 2, 2},
+		{"addHeader", 1, 2},
+		{"setHeader", 1, 2},
+		{"resetHeaders", 0, 0},
+		{"setUserAgent", 1, 1},
+		{"setLocation", 2, 3},
+		// Logging and misc
+		{"logData", 1, 1},
+		{"setEventName", 1, 1},
+		{"combineSteps", 0, 1},
+		{"sleep", 1, 1},
+		{"setTimeout", 1, 1},
+		{"setActivityTimeout", 1, 1},
+		{"setABM", 1, 1},
+		{"setMinimumStepSeconds", 1, 1},
+		{"setViewportSize", 2, 2},
+		{"setBrowserSize", 2, 2},
+		{"clearCache", 0, 0},
+		{"expireCache", 1, 1},
+		{"firefoxPref", 2, 2},
+		{"minInterval", 2, 2},
+		{"reportData", 0, 0},
+	} {
+		scriptCommands[strings.ToLower(spec.Name)] = spec
+	}
+}
+
+// ParseScript parses text of WebPagetest script into commands. Empty lines and comments
+// (lines that start with "//") are skipped, Line of each command is 1-based line number
+func ParseScript(text string) Script {
+	script := make(Script, 0)
+	for idx, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+
+		fields := strings.Split(strings.TrimLeft(line, " \t"), "\t")
+		script = append(script, ScriptCommand{
+			Name: strings.TrimSpace(fields[0]),
+			Args: fields[1:],
+			Line: idx + 1,
+		})
+	}
+	return script
+}
+
+// Steps returns number of steps, that script will record: every navigation
+// with logData on is a step, unless they are combined with combineSteps
+func (s Script) Steps() int {
+	counter := newStepCounter()
+	for _, command := range s {
+		counter.add(command)
+	}
+	return counter.steps
+}
+
+// ScriptIssue is a problem, that linter found in script
+type ScriptIssue struct {
+	Line    int
+	Command string
+	Message string
+}
+
+func (i ScriptIssue) String() string {
+	if i.Command == "" {
+		return fmt.Sprintf("line %d: %s", i.Line, i.Message)
+	}
+	return fmt.Sprintf("line %d: %s: %s", i.Line, i.Command, i.Message)
+}
+
+// LintOptions is options for LintScript
+type LintOptions struct {
+	// Maximum number of steps script is expected to record, 0 to not check
+	ExpectedSteps int
+}
+
+// LintScript checks text of WebPagetest script for unknown commands, wrong number of arguments,
+// spaces used instead of tabs, recorded steps without name and more steps than expected
+func LintScript(text string, options LintOptions) []ScriptIssue {
+	issues := make([]ScriptIssue, 0)
+	script := ParseScript(text)
+
+	counter := newStepCounter()
+	usesLogData := false
+	named := false
+	// Issues about steps without name, they are only reported if script records more than one step
+	unnamed := make(map[int]bool)
+	for _, command := range script {
+		started := counter.add(command)
+		name := strings.ToLower(command.Name)
+		spec, known := scriptCommands[name]
+
+		// "navigate http://example.com" - spaces instead of tab
+		spaced := false
+		display := command.Name
+		if strings.ContainsAny(command.Name, " ") {
+			first := strings.Fields(command.Name)[0]
+			if _, ok := scriptCommands[strings.ToLower(first)]; ok {
+				issues = append(issues, ScriptIssue{command.Line, first, "arguments must be separated with tabs, not spaces"})
+				name, display, spaced = strings.ToLower(first), first, true
+			}
+		}
+		switch {
+		case spaced:
+		case !known:
+			issues = append(issues, ScriptIssue{command.Line, command.Name, "unknown command"})
+		default:
+			if command.Name != spec.Name {
+				issues = append(issues, ScriptIssue{command.Line, command.Name, fmt.Sprintf("command should be spelled %q", spec.Name)})
+			}
+			if len(command.Args) < spec.MinArgs || len(command.Args) > spec.MaxArgs {
+				issues = append(issues, ScriptIssue{command.Line, command.Name, argsMessage(spec, len(command.Args))})
+			}
+		}
+		for argIdx, arg := range command.Args {
+			if arg == "" && argIdx < len(command.Args)-1 {
+				issues = append(issues, ScriptIssue{command.Line, command.Name, "empty argument, probably double tab"})
+			}
+		}
+
+		switch name {
+		case "logdata":
+			usesLogData = true
+		case "seteventname":
+			named = true
+		}
+		// Not a step, not logged or merged into combined step
+		if !started {
+			continue
+		}
+
+		steps := counter.steps
+		if !named {
+			unnamed[len(issues)] = true
+			issues = append(issues, ScriptIssue{command.Line, display, fmt.Sprintf("step %d has no name, use setEventName before it", steps)})
+		}
+		named = false
+		if options.ExpectedSteps > 0 && steps > options.ExpectedSteps {
+			issues = append(issues, ScriptIssue{command.Line, display,
+				fmt.Sprintf("step %d is more than expected %d steps", steps, options.ExpectedSteps)})
+		}
+	}
+
+	// Single step doesn't need a name
+	if counter.steps <= 1 && len(unnamed) > 0 {
+		filtered := make([]ScriptIssue, 0, len(issues))
+		for idx, issue := range issues {
+			if !unnamed[idx] {
+				filtered = append(filtered, issue)
+			}
+		}
+		issues = filtered
+	}
+	if !usesLogData && counter.steps > 1 {
+		issues = append(issues, ScriptIssue{1, "", fmt.Sprintf("script records %d steps, but never uses logData, so all navigations are recorded", counter.steps)})
+	}
+	return issues
+}
+
+func argsMessage(spec scriptCommandSpec, got int) string {
+	if spec.MinArgs == spec.MaxArgs {
+		return fmt.Sprintf("expects %d argument(s), got %d", spec.MinArgs, got)
+	}
+	return fmt.Sprintf("expects %d to %d arguments, got %d", spec.MinArgs, spec.MaxArgs, got)
+}
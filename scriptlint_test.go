@@ -0,0 +1,68 @@
+package webpagetest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsingScript(t *testing.T) {
+	script := ParseScript("// login flow\r\nlogData\t0\r\n\r\nnavigate\thttp://example.com/login\r\nsetValue\tname=user\tme\r\n")
+	assert.Equal(t, Script{
+		{Name: "logData", Args: []string{"0"}, Line: 2},
+		{Name: "navigate", Args: []string{"http://example.com/login"}, Line: 4},
+		{Name: "setValue", Args: []string{"name=user", "me"}, Line: 5},
+	}, script)
+	assert.Equal(t, 0, script.Steps())
+}
+
+func TestScriptSteps(t *testing.T) {
+	assert.Equal(t, 3, ParseScript("navigate\ta\nclickAndWait\tb\nexecAndWait\tc").Steps())
+	assert.Equal(t, 1, ParseScript("combineSteps\nnavigate\ta\nclickAndWait\tb\nexecAndWait\tc").Steps())
+	assert.Equal(t, 2, ParseScript("combineSteps\t2\nnavigate\ta\nclickAndWait\tb\nexecAndWait\tc").Steps())
+	assert.Equal(t, 2, ParseScript("logData\t0\nnavigate\ta\nlogData\t1\nclickAndWait\tb\nexecAndWait\tc").Steps())
+	// Indented commands and commands with spaces instead of tabs
+	assert.Equal(t, 3, ParseScript("\tnavigate\ta\n  clickAndWait id=b\nlogData 0\nexecAndWait\tc\nlogData 1\nexecAndWait c").Steps())
+}
+
+func TestLintingScript(t *testing.T) {
+	text := "logData\t0\n" +
+		"navigate http://example.com/login\n" +
+		"logData\t1\n" +
+		"setEventName\tlogin\n" +
+		"setValue\tname=user\n" +
+		"submitFormAndWait\tname=login\n" +
+		"clickAndWait\tid=next\n" +
+		"frobnicate\n"
+
+	issues := LintScript(text, LintOptions{ExpectedSteps: 1})
+	messages := make([]string, 0)
+	for _, issue := range issues {
+		messages = append(messages, issue.String())
+	}
+	assert.Equal(t, []string{
+		"line 2: navigate: arguments must be separated with tabs, not spaces",
+		"line 5: setValue: expects 2 argument(s), got 1",
+		"line 7: clickAndWait: step 2 has no name, use setEventName before it",
+		"line 7: clickAndWait: step 2 is more than expected 1 steps",
+		"line 8: frobnicate: unknown command",
+	}, messages)
+
+	issues = LintScript("navigate\thttp://example.com/\nclickAndWait\tid=next\n", LintOptions{})
+	assert.Len(t, issues, 3)
+	assert.Contains(t, issues[2].Message, "never uses logData")
+
+	// Single step doesn't need name
+	assert.Empty(t, LintScript("logData\t1\n\tnavigate\thttp://example.com/\n", LintOptions{}))
+
+	issues = LintScript("logData 1\nsetEventName\thome\nnavigate\thttp://example.com/\nclickAndWait id=next\n", LintOptions{})
+	messages = make([]string, 0)
+	for _, issue := range issues {
+		messages = append(messages, issue.String())
+	}
+	assert.Equal(t, []string{
+		"line 1: logData: arguments must be separated with tabs, not spaces",
+		"line 4: clickAndWait: arguments must be separated with tabs, not spaces",
+		"line 4: clickAndWait: step 2 has no name, use setEventName before it",
+	}, messages)
+}
@@ -0,0 +1,169 @@
+package webpagetest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// ConfidenceInterval is range, that contains true value with probability Level
+type ConfidenceInterval struct {
+	Level float64 // 0.95
+	Low   float64
+	High  float64
+}
+
+// Stats is statistical summary of metric across successful runs of one view and step
+type Stats struct {
+	Metric string
+	Step   int // 0-based
+	Cached bool
+
+	// Number of runs with value and number of failed runs, that were left out
+	Count  int
+	Failed int
+
+	Min    float64
+	Max    float64
+	Mean   float64
+	StdDev float64 // sample standard deviation
+	// Coefficient of variation, StdDev / Mean (0.05 is 5%)
+	CV float64
+
+	// Percentiles with nearest-rank method, P50 is the same value as GetMedianRun selects
+	P50 float64
+	P75 float64
+	P90 float64
+	P95 float64
+
+	// Bootstrap confidence intervals of mean and median
+	MeanCI   ConfidenceInterval
+	MedianCI ConfidenceInterval
+
+	// Values of all runs in ascending order
+	Values []float64
+}
+
+func (s *Stats) String() string {
+	return fmt.Sprintf("%s: n=%d mean=%.1f sd=%.1f cv=%.1f%% min=%.1f p50=%.1f p75=%.1f p90=%.1f p95=%.1f max=%.1f, mean %.0f%% CI [%.1f, %.1f]",
+		s.Metric, s.Count, s.Mean, s.StdDev, s.CV*100, s.Min, s.P50, s.P75, s.P90, s.P95, s.Max,
+		s.MeanCI.Level*100, s.MeanCI.Low, s.MeanCI.High)
+}
+
+// StatsOptions is options for ResultData.Stats
+type StatsOptions struct {
+	// Confidence level of intervals, 0.95 by default
+	Confidence float64
+	// Number of bootstrap resamples, 1000 by default
+	Resamples int
+	// Seed of random generator for resampling, so results are reproducible
+	Seed int64
+}
+
+// Stats calculates statistical summary of metric in given step (0-based) of first or repeat view.
+// Metric is any metric, that TestStep.Metric accepts. Failed runs are left out
+func (rd *ResultData) Stats(cached bool, step int, metric string, options StatsOptions) (*Stats, error) {
+	runs := rd.metricValues(cached, step, metric)
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("no successful runs with metric %s in step %d", metric, step+1)
+	}
+
+	values := make([]float64, 0, len(runs))
+	for _, run := range runs {
+		values = append(values, run.value)
+	}
+	stats := calculateStats(values, options)
+	stats.Metric = metric
+	stats.Step = step
+	stats.Cached = cached
+
+	for _, run := range rd.Runs {
+		view := run.FirstView
+		if cached {
+			view = run.RepeatView
+		}
+		if step < len(view.Steps) && !view.Steps[step].Successful() {
+			stats.Failed++
+		}
+	}
+	return stats, nil
+}
+
+// calculateStats returns summary of given values, values are sorted in place
+func calculateStats(values []float64, options StatsOptions) *Stats {
+	if options.Confidence <= 0 || options.Confidence >= 1 {
+		options.Confidence = 0.95
+	}
+	if options.Resamples <= 0 {
+		options.Resamples = 1000
+	}
+
+	sort.Float64s(values)
+	stats := &Stats{
+		Count:  len(values),
+		Min:    values[0],
+		Max:    values[len(values)-1],
+		Mean:   mean(values),
+		P50:    percentile(values, 50),
+		P75:    percentile(values, 75),
+		P90:    percentile(values, 90),
+		P95:    percentile(values, 95),
+		Values: values,
+	}
+	if len(values) > 1 {
+		var sum float64
+		for _, value := range values {
+			sum += (value - stats.Mean) * (value - stats.Mean)
+		}
+		stats.StdDev = math.Sqrt(sum / float64(len(values)-1))
+	}
+	if stats.Mean != 0 {
+		stats.CV = stats.StdDev / math.Abs(stats.Mean)
+	}
+
+	stats.MeanCI, stats.MedianCI = bootstrap(values, options)
+	return stats
+}
+
+// bootstrap estimates confidence intervals of mean and median with percentile method
+func bootstrap(values []float64, options StatsOptions) (ConfidenceInterval, ConfidenceInterval) {
+	meanCI := ConfidenceInterval{Level: options.Confidence, Low: mean(values), High: mean(values)}
+	medianCI := ConfidenceInterval{Level: options.Confidence, Low: percentile(values, 50), High: percentile(values, 50)}
+	if len(values) < 2 {
+		return meanCI, medianCI
+	}
+
+	random := rand.New(rand.NewSource(options.Seed))
+	means := make([]float64, options.Resamples)
+	medians := make([]float64, options.Resamples)
+	sample := make([]float64, len(values))
+	for idx := range means {
+		for j := range sample {
+			sample[j] = values[random.Intn(len(values))]
+		}
+		sort.Float64s(sample)
+		means[idx] = mean(sample)
+		medians[idx] = percentile(sample, 50)
+	}
+	sort.Float64s(means)
+	sort.Float64s(medians)
+
+	tail := (1 - options.Confidence) / 2 * 100
+	meanCI.Low, meanCI.High = percentile(means, tail), percentile(means, 100-tail)
+	medianCI.Low, medianCI.High = percentile(medians, tail), percentile(medians, 100-tail)
+	return meanCI, medianCI
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+// percentile returns value at given percentile of sorted values with nearest-rank method
+func percentile(values []float64, p float64) float64 {
+	return values[percentileRank(len(values), p)]
+}
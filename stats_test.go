@@ -0,0 +1,60 @@
+package webpagetest
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	result := testResult([]int{900, 100, 800, 200, 700, 300, 600, 400, 500}, []int{50, 10, 40, 20, 30})
+	result.Runs["1"].FirstView.Steps[0].Result = 404
+
+	stats, err := result.Stats(false, 0, "loadTime", StatsOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 8, stats.Count)
+	assert.Equal(t, 1, stats.Failed)
+	assert.Equal(t, []float64{100, 200, 300, 400, 500, 600, 700, 800}, stats.Values)
+	assert.Equal(t, 100.0, stats.Min)
+	assert.Equal(t, 800.0, stats.Max)
+	assert.Equal(t, 450.0, stats.Mean)
+	assert.InDelta(t, 244.95, stats.StdDev, 0.01)
+	assert.InDelta(t, 0.544, stats.CV, 0.001)
+	assert.Equal(t, 400.0, stats.P50)
+	assert.Equal(t, 600.0, stats.P75)
+	assert.Equal(t, 800.0, stats.P90)
+	assert.Equal(t, 800.0, stats.P95)
+
+	// Median value is the same as of median run
+	median, err := result.GetMedianRun(0, "loadTime")
+	assert.Nil(t, err)
+	assert.Equal(t, stats.P50, float64(median.FirstView.Steps[0].LoadTime))
+
+	assert.Equal(t, 0.95, stats.MeanCI.Level)
+	assert.True(t, stats.MeanCI.Low < stats.Mean && stats.Mean < stats.MeanCI.High, "%+v", stats.MeanCI)
+	assert.True(t, stats.MedianCI.Low <= stats.P50 && stats.P50 <= stats.MedianCI.High, "%+v", stats.MedianCI)
+
+	// Same seed gives same intervals
+	again, err := result.Stats(false, 0, "loadTime", StatsOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, stats.MeanCI, again.MeanCI)
+
+	repeat, err := result.Stats(true, 0, "loadTime", StatsOptions{Confidence: 0.9, Resamples: 200})
+	assert.Nil(t, err)
+	assert.Equal(t, 5, repeat.Count)
+	assert.Equal(t, 30.0, repeat.Mean)
+	assert.Equal(t, 0.9, repeat.MeanCI.Level)
+
+	_, err = result.Stats(false, 0, "noSuchMetric", StatsOptions{})
+	assert.NotNil(t, err)
+}
+
+func TestStatsSingleRun(t *testing.T) {
+	stats, err := testResult([]int{1234}, nil).Stats(false, 0, "loadTime", StatsOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, stats.Count)
+	assert.Equal(t, 0.0, stats.StdDev)
+	assert.Equal(t, ConfidenceInterval{Level: 0.95, Low: 1234, High: 1234}, stats.MeanCI)
+	assert.False(t, math.IsNaN(stats.CV))
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
-	"strconv"
 )
 
 // testStatus.php
@@ -116,42 +115,6 @@ import (
 }
 */
 
-// TestInfo is info about test
-type TestInfo struct {
-	URL           string `json:"url"`
-	Runs          int    `json:"runs"`
-	FirstViewOnly int    `json:"fvonly"`
-	Web10         int    `json:"web10"`     // Stop Test at Document Complete
-	IgnoreSSL     int    `json:"ignoreSSL"` // Ignore SSL Certificate Errors
-	Video         string `json:"video"`
-	Label         string `json:"label"`
-	Priority      int    `json:"priority"`
-	Location      string `json:"location"`
-	Browser       string `json:"browser"`
-
-	Connectivity string `json:"connectivity"`
-	BandwidthIn  int    `json:"bwIn"`
-	BandwidthOut int    `json:"bwOut"`
-	Latency      int    `json:"latency"`
-
-	// It can be string or int
-	RawPacketLossRate *json.RawMessage `json:"plr"`
-	PacketLossRate    int
-
-	Tcpdump      int `json:"tcpdump"`  // Capture network packet trace (tcpdump)
-	Timeline     int `json:"timeline"` // Capture Dev Tools Timeline
-	Trace        int `json:"trace"`    // Capture Chrome Trace (about://tracing)
-	Bodies       int `json:"bodies"`
-	NetLog       int `json:"netlog"`    // Capture Network Log
-	Standards    int `json:"standards"` // Disable Compatibility View (IE Only)
-	NoScript     int `json:"noscript"`  // Disable Javascript
-	Pngss        int `json:"pngss"`
-	ImageQuality int `json:"iq"`
-	KeepUA       int `json:"keepua"` // Preserve original User Agent string
-	Mobile       int `json:"mobile"`
-	Scripted     int `json:"scripted"`
-}
-
 // TestStatus is status of a test
 type TestStatus struct {
 	StatusCode int    `json:"statusCode"`
@@ -167,7 +130,7 @@ type TestStatus struct {
 	BehindCount int `json:"behindCount"`
 
 	Remote         bool `json:"remote"` // Relay Test
-	FirstViewOnly  int  `json:"fvonly"`
+	FirstViewOnly  bool `json:"fvonly"`
 	Elapsed        int  `json:"elapsed"`
 	ElapsedUpdate  int  `json:"elapsedUpdate"`
 	TestsExpected  int  `json:"testsExpected"`
@@ -179,6 +142,68 @@ type TestStatus struct {
 	TestInfo TestInfo `json:"testInfo"`
 }
 
+type jsonTestStatusData struct {
+	StatusCode int    `json:"statusCode"`
+	StatusText string `json:"statusText"`
+
+	ID           string `json:"id"`
+	TestID       string `json:"testId"`
+	Location     string `json:"location"`
+	StartTime    string `json:"startTime"`
+	CompleteTime string `json:"completeTime"`
+
+	Runs        FlexInt `json:"runs"`
+	BehindCount FlexInt `json:"behindCount"`
+
+	Remote         FlexBool `json:"remote"`
+	FirstViewOnly  FlexBool `json:"fvonly"`
+	Elapsed        FlexInt  `json:"elapsed"`
+	ElapsedUpdate  FlexInt  `json:"elapsedUpdate"`
+	TestsExpected  FlexInt  `json:"testsExpected"`
+	TestsCompleted FlexInt  `json:"testsCompleted"`
+
+	FirstViewRunsCompleted  FlexInt `json:"fvRunsCompleted"`
+	RepeatViewRunsCompleted FlexInt `json:"rvRunsCompleted"`
+
+	TestInfo TestInfo `json:"testInfo"`
+}
+
+// UnmarshalJSON implements custom unmarshaling logic, because WebPagetest
+// returns numbers and flags both as numbers and as strings
+func (ts *TestStatus) UnmarshalJSON(b []byte) error {
+	var raw jsonTestStatusData
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*ts = TestStatus{
+		StatusCode: raw.StatusCode,
+		StatusText: raw.StatusText,
+
+		ID:           raw.ID,
+		TestID:       raw.TestID,
+		Location:     raw.Location,
+		StartTime:    raw.StartTime,
+		CompleteTime: raw.CompleteTime,
+
+		Runs:        int(raw.Runs),
+		BehindCount: int(raw.BehindCount),
+
+		Remote:         bool(raw.Remote),
+		FirstViewOnly:  bool(raw.FirstViewOnly),
+		Elapsed:        int(raw.Elapsed),
+		ElapsedUpdate:  int(raw.ElapsedUpdate),
+		TestsExpected:  int(raw.TestsExpected),
+		TestsCompleted: int(raw.TestsCompleted),
+
+		FirstViewRunsCompleted:  int(raw.FirstViewRunsCompleted),
+		RepeatViewRunsCompleted: int(raw.RepeatViewRunsCompleted),
+
+		TestInfo: raw.TestInfo,
+	}
+	return nil
+}
+
 type jsonTestStatus struct {
 	StatusCode int    `json:"statusCode"`
 	StatusText string `json:"statusText"`
@@ -200,13 +225,6 @@ func (c *Client) GetTestStatus(testID string) (*TestStatus, error) {
 		return nil, err
 	}
 
-	// Unset value is "0"
-	if result.Data.TestInfo.RawPacketLossRate == nil || string(*result.Data.TestInfo.RawPacketLossRate) == "\"0\"" {
-		result.Data.TestInfo.PacketLossRate = 0
-	} else {
-		result.Data.TestInfo.PacketLossRate, _ = strconv.Atoi(string(*result.Data.TestInfo.RawPacketLossRate))
-	}
-
 	if result.StatusCode > 200 {
 		return nil, fmt.Errorf("%s", result.StatusText)
 	}
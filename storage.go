@@ -0,0 +1,104 @@
+package webpagetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Stored result is "data" section of jsonResult.php response with format version:
+// every view has "steps" array, even if test has only one step, and all step
+// fields unknown to this library are kept as they were
+
+/*
+{
+  "formatVersion": 1,
+  "id": "161126_19_12569a3f0de7a2fec98475b5d8bb0d37",
+  "url": "http://google.com",
+  ...
+  "runs": {
+    "1": {
+      "firstView": {
+        "run": 1,
+        "tester": "VM1-01-192.168.10.43",
+        "numSteps": 1,
+        "steps": [{"loadTime": 1234, ...}]
+      }
+    }
+  }
+}
+*/
+
+// ResultFormatVersion is version of json format, that ResultData is marshaled to
+const ResultFormatVersion = 1
+
+// MarshalJSON implements json.Marshaler, result is encoded in stored format,
+// that can be decoded back with json.Unmarshal or LoadResult without loss
+func (rd ResultData) MarshalJSON() ([]byte, error) {
+	type resultData ResultData
+	return json.Marshal(struct {
+		FormatVersion int `json:"formatVersion"`
+		resultData
+	}{ResultFormatVersion, resultData(rd)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler, it accepts both stored format
+// and "data" section of jsonResult.php response
+func (rd *ResultData) UnmarshalJSON(b []byte) error {
+	var stored struct {
+		FormatVersion int `json:"formatVersion"`
+	}
+	if err := json.Unmarshal(b, &stored); err != nil {
+		return err
+	}
+	if stored.FormatVersion > ResultFormatVersion {
+		return fmt.Errorf("unsupported result format version %d, max supported is %d",
+			stored.FormatVersion, ResultFormatVersion)
+	}
+
+	// Runs may be already decoded by ParseResult, that sets other fields afterwards
+	raw := jsonResultData{Runs: rd.Runs}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*rd = ResultData{
+		Connectivity: Connectivity{
+			Name:           raw.Connectivity,
+			BandwidthDown:  int(raw.BandwidthDown),
+			BandwidthUp:    int(raw.BandwidthUp),
+			Latency:        int(raw.Latency),
+			PacketLossRate: float64(raw.PacketLossRate),
+		},
+
+		ID:       raw.ID,
+		URL:      raw.URL,
+		Summary:  raw.Summary,
+		TestURL:  raw.TestURL,
+		Location: raw.Location,
+		Label:    raw.Label,
+		From:     raw.From,
+
+		Mobile:           bool(raw.Mobile),
+		Completed:        int(raw.Completed),
+		Tester:           raw.Tester,
+		TesterDNS:        raw.TesterDNS,
+		FirstViewOnly:    bool(raw.FirstViewOnly),
+		SuccessfulFVRuns: int(raw.SuccessfulFVRuns),
+		SuccessfulRVRuns: int(raw.SuccessfulRVRuns),
+
+		Runs:          raw.Runs,
+		RawLighthouse: compactJSON(raw.RawLighthouse),
+	}
+	return nil
+}
+
+// SaveResult writes result in stored format
+func SaveResult(w io.Writer, result *ResultData) error {
+	return json.NewEncoder(w).Encode(result)
+}
+
+// LoadResult reads result, that was written with SaveResult, or response of jsonResult.php
+func LoadResult(r io.Reader) (*ResultData, error) {
+	return ParseResult(r)
+}
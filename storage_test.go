@@ -0,0 +1,79 @@
+package webpagetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultRoundTrip(t *testing.T) {
+	for _, name := range []string{"TestResultPlrAsNumber.json", "TestResultPlrAsString.json"} {
+		response, err := ioutil.ReadFile("./testdata/" + name)
+		assert.Nil(t, err)
+		original, err := parseResultResponse(response)
+		assert.Nil(t, err)
+
+		var buf bytes.Buffer
+		assert.Nil(t, SaveResult(&buf, original))
+		assert.Contains(t, buf.String(), `"formatVersion":1`)
+
+		loaded, err := LoadResult(&buf)
+		assert.Nil(t, err, name)
+		assert.Equal(t, original, loaded, name)
+
+		// Second round gives exactly the same json
+		first, err := json.Marshal(original)
+		assert.Nil(t, err)
+		second, err := json.Marshal(loaded)
+		assert.Nil(t, err)
+		assert.JSONEq(t, string(first), string(second), name)
+	}
+}
+
+func TestResultRoundTripKeepsExtraFields(t *testing.T) {
+	var result ResultData
+	assert.Nil(t, json.Unmarshal([]byte(`{
+		"id": "test",
+		"fvonly": true,
+		"runs": {"1": {"firstView": {"run": 1, "tester": "agent", "loadTime": 1000,
+			"domains": {"example.com": {"bytes": 100, "requests": 2}},
+			"newMetric": 42, "userTimingMeasures": [{"name": "m", "startTime": 1, "duration": 2}]}}}
+	}`), &result))
+
+	view := result.Runs["1"].FirstView
+	assert.Equal(t, 1, view.NumberOfSteps)
+	assert.Equal(t, 100, view.Steps[0].Domains["example.com"].Bytes)
+
+	data, err := json.Marshal(result)
+	assert.Nil(t, err)
+
+	var loaded ResultData
+	assert.Nil(t, json.Unmarshal(data, &loaded))
+	assert.Equal(t, result, loaded)
+
+	value, ok := loaded.Runs["1"].FirstView.Steps[0].Metric("newMetric")
+	assert.True(t, ok)
+	assert.Equal(t, 42.0, value)
+	assert.Len(t, loaded.Runs["1"].FirstView.Steps[0].UserTimingMeasures, 1)
+	assert.Empty(t, loaded.Runs["1"].RepeatView.Steps)
+}
+
+func TestLoadResult(t *testing.T) {
+	file, err := os.Open("./testdata/TestResultPlrAsNumber.json")
+	assert.Nil(t, err)
+	defer file.Close()
+
+	result, err := LoadResult(file)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, result.Runs)
+
+	_, err = LoadResult(bytes.NewBufferString(`{"statusCode": 400, "statusText": "Invalid test ID", "data": {}}`))
+	assert.NotNil(t, err)
+
+	_, err = LoadResult(bytes.NewBufferString(`{"formatVersion": 99, "id": "test"}`))
+	assert.NotNil(t, err)
+}
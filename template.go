@@ -0,0 +1,247 @@
+package webpagetest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+logData	0
+navigate	https://${host}/login
+logData	1
+setValue	name=username	${user=tester}
+setValue	name=password	${secret:LOGIN_PASSWORD}
+submitFormAndWait	name=loginForm
+*/
+
+// Redacted is what secret values are replaced with in logs, errors and stored TestSettings
+const Redacted = "[REDACTED]"
+
+// SecretProvider resolves secret references like ${secret:name} in script templates
+type SecretProvider interface {
+	Secret(name string) (string, error)
+}
+
+// SecretProviderFunc is adapter to use ordinary function as SecretProvider
+type SecretProviderFunc func(name string) (string, error)
+
+// Secret calls f(name)
+func (f SecretProviderFunc) Secret(name string) (string, error) {
+	return f(name)
+}
+
+// EnvSecrets resolves secrets from environment variables, ${secret:name}
+// is read from variable Prefix+name
+type EnvSecrets struct {
+	Prefix string
+}
+
+// Secret returns value of environment variable
+func (e EnvSecrets) Secret(name string) (string, error) {
+	value, ok := os.LookupEnv(e.Prefix + name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", e.Prefix+name)
+	}
+	return value, nil
+}
+
+// ${name}, ${name=default} or ${secret:name}
+var templatePlaceholder = regexp.MustCompile(`\$\{(secret:)?([A-Za-z_][A-Za-z0-9_.-]*)(=[^}]*)?\}`)
+
+// ScriptTemplate is WebPagetest script with named variables and secret references
+type ScriptTemplate struct {
+	Text string
+	// Used to resolve ${secret:name} references, environment variables by default
+	Secrets SecretProvider
+}
+
+// NewScriptTemplate checks given template text and returns ScriptTemplate,
+// that resolves secrets from environment variables
+func NewScriptTemplate(text string) (*ScriptTemplate, error) {
+	for _, match := range templatePlaceholder.FindAllStringSubmatch(text, -1) {
+		if match[1] != "" && match[3] != "" {
+			return nil, fmt.Errorf("secret %s can't have default value", match[2])
+		}
+	}
+	if rest := templatePlaceholder.ReplaceAllString(text, ""); strings.Contains(rest, "${") {
+		return nil, fmt.Errorf("malformed placeholder near %q", rest[strings.Index(rest, "${"):])
+	}
+	return &ScriptTemplate{Text: text, Secrets: EnvSecrets{}}, nil
+}
+
+// Variables returns names of all variables (not secrets) used in template
+func (t *ScriptTemplate) Variables() []string {
+	return t.placeholders(false)
+}
+
+// SecretNames returns names of all secrets referenced in template
+func (t *ScriptTemplate) SecretNames() []string {
+	return t.placeholders(true)
+}
+
+func (t *ScriptTemplate) placeholders(secrets bool) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0)
+	for _, match := range templatePlaceholder.FindAllStringSubmatch(t.Text, -1) {
+		if (match[1] != "") != secrets || seen[match[2]] {
+			continue
+		}
+		seen[match[2]] = true
+		result = append(result, match[2])
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Render substitutes variables and secrets, it returns rendered script and resolved secret values.
+// Errors never include values of secrets
+func (t *ScriptTemplate) Render(vars map[string]string) (string, []string, error) {
+	secrets := make([]string, 0)
+	var errs []string
+
+	rendered := templatePlaceholder.ReplaceAllStringFunc(t.Text, func(placeholder string) string {
+		match := templatePlaceholder.FindStringSubmatch(placeholder)
+		isSecret, name, def := match[1] != "", match[2], match[3]
+
+		var value string
+		if isSecret {
+			provider := t.Secrets
+			if provider == nil {
+				provider = EnvSecrets{}
+			}
+			secret, err := provider.Secret(name)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("secret %s: %v", name, err))
+				return placeholder
+			}
+			if secret != "" {
+				secrets = append(secrets, secret)
+			}
+			value = secret
+		} else {
+			var ok bool
+			if value, ok = vars[name]; !ok {
+				if def == "" {
+					errs = append(errs, fmt.Sprintf("variable %s is not set", name))
+					return placeholder
+				}
+				value = def[1:]
+			}
+		}
+
+		if strings.ContainsAny(value, "\t\r\n") {
+			errs = append(errs, fmt.Sprintf("value of %s contains tab or line break", name))
+			return placeholder
+		}
+		return value
+	})
+
+	if len(errs) > 0 {
+		return "", nil, errors.New(redact("failed to render script: "+strings.Join(errs, "; "), secrets))
+	}
+	return rendered, secrets, nil
+}
+
+// Apply renders template and sets it as script of given settings. Secret values are remembered
+// by settings, so they are redacted when settings are printed or marshaled to JSON
+func (t *ScriptTemplate) Apply(settings *TestSettings, vars map[string]string) error {
+	script, secrets, err := t.Render(vars)
+	if err != nil {
+		return err
+	}
+	settings.Script = script
+	values := append(append([]string{}, settings.secrets.list()...), secrets...)
+	settings.secrets = &secretValues{values: values}
+	return nil
+}
+
+// secretValues holds values of secrets applied to TestSettings. It's kept behind
+// a pointer, so TestSettings stays comparable with ==
+type secretValues struct {
+	values []string
+}
+
+// list returns values of secrets, it's safe to call on nil
+func (v *secretValues) list() []string {
+	if v == nil {
+		return nil
+	}
+	return v.values
+}
+
+// redact replaces all given secrets in text with Redacted, longest first,
+// so secret that contains another one is fully hidden. Secrets are also
+// searched in escaped form, as they appear in JSON or %#v output
+func redact(text string, secrets []string) string {
+	forms := make([]string, 0, len(secrets)*3)
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		quoted := strconv.Quote(secret)
+		encoded, _ := json.Marshal(secret)
+		forms = append(forms, secret, quoted[1:len(quoted)-1], string(encoded[1:len(encoded)-1]))
+	}
+
+	sort.Slice(forms, func(i, j int) bool { return len(forms[i]) > len(forms[j]) })
+	for _, form := range forms {
+		text = strings.Replace(text, form, Redacted, -1)
+	}
+	return text
+}
+
+// Redact replaces values of secrets, that were set with ScriptTemplate.Apply, in given text
+func (s TestSettings) Redact(text string) string {
+	return redact(text, s.secrets.list())
+}
+
+// redactError returns err with secrets redacted from its message
+func (s TestSettings) redactError(err error) error {
+	if err == nil || len(s.secrets.list()) == 0 {
+		return err
+	}
+	return errors.New(s.Redact(err.Error()))
+}
+
+// testSettings is used to marshal and print TestSettings without recursion
+type testSettings TestSettings
+
+// redacted returns copy of settings with secrets redacted from all string fields,
+// so redaction never touches JSON keys or formatting of other values
+func (s TestSettings) redacted() testSettings {
+	out := testSettings(s)
+	if len(s.secrets.list()) == 0 {
+		return out
+	}
+	out.secrets = nil
+	value := reflect.ValueOf(&out).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		if field.Kind() == reflect.String && field.CanSet() {
+			field.SetString(redact(field.String(), s.secrets.list()))
+		}
+	}
+	return out
+}
+
+// MarshalJSON implements json.Marshaler, values of secrets are redacted
+func (s TestSettings) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.redacted())
+}
+
+// String implements fmt.Stringer, values of secrets are redacted
+func (s TestSettings) String() string {
+	return fmt.Sprintf("%+v", s.redacted())
+}
+
+// GoString implements fmt.GoStringer, values of secrets are redacted
+func (s TestSettings) GoString() string {
+	return fmt.Sprintf("%#v", s.redacted())
+}
@@ -0,0 +1,87 @@
+package webpagetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testScriptTemplate = "logData\t0\n" +
+	"navigate\thttps://${host}/login\n" +
+	"logData\t1\n" +
+	"setValue\tname=username\t${user=tester}\n" +
+	"setValue\tname=password\t${secret:LOGIN_PASSWORD}\n" +
+	"submitFormAndWait\tname=loginForm"
+
+func testSecrets(name string) (string, error) {
+	if name == "LOGIN_PASSWORD" {
+		return "s3cr3t\"pw", nil
+	}
+	return "", fmt.Errorf("unknown secret")
+}
+
+func TestRenderingScriptTemplate(t *testing.T) {
+	template, err := NewScriptTemplate(testScriptTemplate)
+	assert.Nil(t, err)
+	template.Secrets = SecretProviderFunc(testSecrets)
+
+	assert.Equal(t, []string{"host", "user"}, template.Variables())
+	assert.Equal(t, []string{"LOGIN_PASSWORD"}, template.SecretNames())
+
+	var settings TestSettings
+	assert.Nil(t, template.Apply(&settings, map[string]string{"host": "stage.example.com"}))
+	assert.Contains(t, settings.Script, "navigate\thttps://stage.example.com/login\n")
+	assert.Contains(t, settings.Script, "setValue\tname=username\ttester\n")
+	assert.Contains(t, settings.Script, "setValue\tname=password\ts3cr3t\"pw\n")
+
+	stored, err := json.Marshal(settings)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(stored), "s3cr3t")
+	assert.Contains(t, string(stored), "name=password\\t[REDACTED]")
+
+	assert.NotContains(t, fmt.Sprintf("%v", settings), "s3cr3t")
+	assert.NotContains(t, fmt.Sprintf("%#v", settings), "s3cr3t")
+	assert.Equal(t, "bad password [REDACTED]", settings.redactError(fmt.Errorf("bad password s3cr3t\"pw")).Error())
+}
+
+func TestRedactingShortSecrets(t *testing.T) {
+	template, err := NewScriptTemplate("setValue\tname=pin\t${secret:PIN}")
+	assert.Nil(t, err)
+	template.Secrets = SecretProviderFunc(func(string) (string, error) { return "1", nil })
+
+	settings := TestSettings{URL: "http://example.com/", Runs: 1, Private: true}
+	assert.Nil(t, template.Apply(&settings, nil))
+
+	stored, err := json.Marshal(settings)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"URL":"http://example.com/","Runs":1,"Script":"setValue\tname=pin\t[REDACTED]","Private":true}`, string(stored))
+	assert.Contains(t, fmt.Sprintf("%v", settings), "Runs:1 ")
+	assert.Contains(t, fmt.Sprintf("%#v", settings), "Runs:1, ")
+
+	// Settings stay comparable, applying template again doesn't change copies
+	same := settings
+	assert.True(t, same == settings)
+	assert.Nil(t, template.Apply(&same, nil))
+	assert.False(t, same == settings)
+	assert.Equal(t, []string{"1"}, settings.secrets.list())
+	assert.Equal(t, []string{"1", "1"}, same.secrets.list())
+}
+
+func TestRenderingScriptTemplateErrors(t *testing.T) {
+	_, err := NewScriptTemplate("navigate\t${secret:pw=default}")
+	assert.NotNil(t, err)
+	_, err = NewScriptTemplate("navigate\t${host")
+	assert.NotNil(t, err)
+
+	template, err := NewScriptTemplate(testScriptTemplate + "\nsetValue\tid=x\t${secret:OTHER}")
+	assert.Nil(t, err)
+	template.Secrets = SecretProviderFunc(testSecrets)
+
+	_, _, err = template.Render(map[string]string{})
+	assert.Equal(t, "failed to render script: variable host is not set; secret OTHER: unknown secret", err.Error())
+
+	_, _, err = template.Render(map[string]string{"host": "a\tb"})
+	assert.Contains(t, err.Error(), "value of host contains tab")
+}
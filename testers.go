@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
-	"strconv"
 )
 
 // getTesters.php
@@ -124,31 +123,31 @@ type jsonTesterPC struct {
 	Name string `json:"pc"`
 	IP   string `json:"ip"`
 
-	ScreenWidth  string `json:"screenwidth"`  // Screen Size
-	ScreenHeight string `json:"screenheight"` // Screen Size
-
-	EC2            string `json:"ec2"` // EC2 Instance
-	DNS            string `json:"dns"` // DNS Server(s)
-	AgentVersion   string `json:"version"`
-	IEVersion      string `json:"ie"`       // IE Version
-	WindowsVersion string `json:"winver"`   // Windows Version
-	FreeDisk       string `json:"freedisk"` // Free Disk (GB)
-	IsWinServer    string `json:"isWinServer"`
-	IsWin64        string `json:"isWin64"`
-	Offline        string `json:"offline"`
-	Rebooted       bool   `json:"rebooted"`
-	GPU            string `json:"GPU"`
-	CPU            int    `json:"cpu"` // CPU Utilization
-
-	Errors  int `json:"errors"` // Error Rate
-	Elapsed int `json:"elapsed"`
-	Last    int `json:"last"` // Last Work (minutes)
-	Busy    int `json:"busy"` // Busy?
+	ScreenWidth  FlexInt `json:"screenwidth"`  // Screen Size
+	ScreenHeight FlexInt `json:"screenheight"` // Screen Size
+
+	EC2            string    `json:"ec2"` // EC2 Instance
+	DNS            string    `json:"dns"` // DNS Server(s)
+	AgentVersion   string    `json:"version"`
+	IEVersion      string    `json:"ie"`       // IE Version
+	WindowsVersion string    `json:"winver"`   // Windows Version
+	FreeDisk       FlexFloat `json:"freedisk"` // Free Disk (GB)
+	IsWinServer    FlexBool  `json:"isWinServer"`
+	IsWin64        FlexBool  `json:"isWin64"`
+	Offline        FlexBool  `json:"offline"`
+	Rebooted       FlexBool  `json:"rebooted"`
+	GPU            FlexBool  `json:"GPU"`
+	CPU            FlexInt   `json:"cpu"` // CPU Utilization
+
+	Errors  FlexInt  `json:"errors"` // Error Rate
+	Elapsed FlexInt  `json:"elapsed"`
+	Last    FlexInt  `json:"last"` // Last Work (minutes)
+	Busy    FlexBool `json:"busy"` // Busy?
 }
 
 type jsonTester struct {
 	Status  string         `json:"status"`  // "Ok" / "OFFLINE"
-	Elapsed int            `json:"elapsed"` // 0
+	Elapsed FlexInt        `json:"elapsed"` // 0
 	Testers []jsonTesterPC `json:"testers"`
 }
 
@@ -223,10 +222,6 @@ func (c *Client) GetTesters() (*Testers, error) {
 		}
 
 		for _, tester := range data.Testers {
-			freedisk, _ := strconv.ParseFloat(tester.FreeDisk, 32)
-			width, _ := strconv.ParseInt(tester.ScreenWidth, 10, 32)
-			height, _ := strconv.ParseInt(tester.ScreenHeight, 10, 32)
-
 			result[location] = append(result[location], Tester{
 				ID:   tester.ID,
 				Name: tester.Name,
@@ -234,12 +229,12 @@ func (c *Client) GetTesters() (*Testers, error) {
 				AgentVersion: tester.AgentVersion,
 
 				// Status
-				ErrorRate:  tester.Errors,
-				Elapsed:    tester.Elapsed,
-				LastWork:   tester.Last,
-				IsRebooted: tester.Rebooted,
-				IsOffline:  tester.Offline == "1",
-				IsBusy:     tester.Busy == 1,
+				ErrorRate:  int(tester.Errors),
+				Elapsed:    int(tester.Elapsed),
+				LastWork:   int(tester.Last),
+				IsRebooted: bool(tester.Rebooted),
+				IsOffline:  bool(tester.Offline),
+				IsBusy:     bool(tester.Busy),
 
 				// Network
 				EC2: tester.EC2,
@@ -247,19 +242,19 @@ func (c *Client) GetTesters() (*Testers, error) {
 				DNS: tester.DNS,
 
 				// Screen
-				ScreenWidth:  width,
-				ScreenHeight: height,
+				ScreenWidth:  int64(tester.ScreenWidth),
+				ScreenHeight: int64(tester.ScreenHeight),
 
 				// Windows
 				IEVersion:      tester.IEVersion,
 				WindowsVersion: tester.WindowsVersion,
-				IsWinServer:    tester.IsWinServer == "1",
-				IsWin64:        tester.IsWin64 == "1",
+				IsWinServer:    bool(tester.IsWinServer),
+				IsWin64:        bool(tester.IsWin64),
 
 				// Hardware
-				FreeDisk: freedisk,
-				GPU:      tester.GPU == "1",
-				CPU:      tester.CPU,
+				FreeDisk: float64(tester.FreeDisk),
+				GPU:      bool(tester.GPU),
+				CPU:      int(tester.CPU),
 			})
 		}
 	}
@@ -0,0 +1,278 @@
+package webpagetest
+
+import (
+	"encoding/json"
+	"net/url"
+)
+
+// getgzip.php?test=<testId>&file=testinfo.json
+
+/*
+{
+  "url": "http://google.com",
+  "runs": 3,
+  "fvonly": 0,
+  "web10": 0,
+  "ignoreSSL": 0,
+  "video": "on",
+  "label": "test run",
+  "priority": 0,
+  "block": "",
+  "location": "Prague",
+  "browser": "Chrome",
+  "connectivity": "Cable",
+  "bwIn": 5000,
+  "bwOut": 1000,
+  "latency": 28,
+  "plr": "0",
+  "tcpdump": 0,
+  "timeline": 0,
+  "trace": 0,
+  "bodies": 0,
+  "netlog": 0,
+  "standards": 0,
+  "noscript": 0,
+  "pngss": 0,
+  "iq": 0,
+  "keepua": 0,
+  "mobile": 0,
+  "addCmdLine": "",
+  "scripted": 0,
+  "script": "",
+  "custom": "",
+  "id": "161126_19_12569a3f0de7a2fec98475b5d8bb0d37",
+  "owner": "c9d1754ea6388229093c69adac3740e0339fa100",
+  "started": 1480144673,
+  "completed": 1480144745,
+  ...
+}
+*/
+
+type jsonTestInfo struct {
+	URL      string  `json:"url"`
+	Label    string  `json:"label"`
+	Location string  `json:"location"`
+	Browser  string  `json:"browser"`
+	Runs     FlexInt `json:"runs"`
+	Priority FlexInt `json:"priority"`
+
+	ID        string  `json:"id"`
+	Owner     string  `json:"owner"`
+	Started   FlexInt `json:"started"`
+	Completed FlexInt `json:"completed"`
+
+	Connectivity   string    `json:"connectivity"`
+	BandwidthIn    FlexInt   `json:"bwIn"`
+	BandwidthOut   FlexInt   `json:"bwOut"`
+	Latency        FlexInt   `json:"latency"`
+	PacketLossRate FlexFloat `json:"plr"`
+
+	Script        string `json:"script"`
+	Block         string `json:"block"`
+	BlockDomains  string `json:"blockDomains"`
+	CustomMetrics string `json:"custom"`
+	CustomHeaders string `json:"customHeaders"`
+	CmdLine       string `json:"addCmdLine"`
+	InjectScript  string `json:"injectScript"`
+
+	UAString     string    `json:"uastring"`
+	AppendUA     string    `json:"appendua"`
+	MobileDevice string    `json:"mobileDevice"`
+	DPR          FlexFloat `json:"dpr"`
+	Width        FlexInt   `json:"width"`
+	Height       FlexInt   `json:"height"`
+
+	MedianMetric  string  `json:"medianMetric"`
+	Tester        string  `json:"tester"`
+	Affinity      string  `json:"affinity"`
+	ImageQuality  FlexInt `json:"iq"`
+	Connections   FlexInt `json:"connections"`
+	TimelineStack FlexInt `json:"timelineStackDepth"`
+
+	FirstViewOnly FlexBool `json:"fvonly"`
+	Web10         FlexBool `json:"web10"`
+	IgnoreSSL     FlexBool `json:"ignoreSSL"`
+	Video         FlexBool `json:"video"`
+	MedianVideo   FlexBool `json:"mv"`
+	Tcpdump       FlexBool `json:"tcpdump"`
+	Timeline      FlexBool `json:"timeline"`
+	Trace         FlexBool `json:"trace"`
+	Bodies        FlexBool `json:"bodies"`
+	HTMLBody      FlexBool `json:"htmlbody"`
+	NetLog        FlexBool `json:"netlog"`
+	Standards     FlexBool `json:"standards"`
+	NoScript      FlexBool `json:"noscript"`
+	NoOpt         FlexBool `json:"noopt"`
+	NoImages      FlexBool `json:"noimages"`
+	NoHeaders     FlexBool `json:"noheaders"`
+	Pngss         FlexBool `json:"pngss"`
+	KeepUA        FlexBool `json:"keepua"`
+	Mobile        FlexBool `json:"mobile"`
+	ClearCerts    FlexBool `json:"clearcerts"`
+	Private       FlexBool `json:"private"`
+	Scripted      FlexBool `json:"scripted"`
+	Lighthouse    FlexBool `json:"lighthouse"`
+}
+
+// TestInfo is configuration of test, as it was submitted
+type TestInfo struct {
+	ID       string
+	URL      string
+	Label    string
+	Location string
+	Browser  string
+	Runs     int
+	Priority int
+
+	// Owner key of test
+	Owner string
+	// Unix timestamps of test start and completion
+	Started   int
+	Completed int
+
+	// Connectivity profile name and its settings
+	Connectivity   string
+	BandwidthIn    int     // Kbps
+	BandwidthOut   int     // Kbps
+	Latency        int     // ms
+	PacketLossRate float64 // %
+
+	Script        string
+	Block         string // space-delimited list of urls to block
+	BlockDomains  string
+	CustomMetrics string
+	CustomHeaders string
+	CmdLine       string // Custom command-line options (Chrome only)
+	InjectScript  string
+
+	UAString     string
+	AppendUA     string
+	MobileDevice string
+	DPR          float64
+	ScreenWidth  int
+	ScreenHeight int
+
+	MedianMetric  string
+	Tester        string
+	Affinity      string
+	ImageQuality  int
+	Connections   int
+	TimelineStack int
+
+	FirstViewOnly bool
+	Web10         bool // Stop Test at Document Complete
+	IgnoreSSL     bool // Ignore SSL Certificate Errors
+	Video         bool
+	MedianVideo   bool
+	Tcpdump       bool // Capture network packet trace (tcpdump)
+	Timeline      bool // Capture Dev Tools Timeline
+	Trace         bool // Capture Chrome Trace (about://tracing)
+	Bodies        bool
+	HTMLBody      bool
+	NetLog        bool // Capture Network Log
+	Standards     bool // Disable Compatibility View (IE Only)
+	NoScript      bool // Disable Javascript
+	NoOpt         bool
+	NoImages      bool
+	NoHeaders     bool
+	Pngss         bool
+	KeepUA        bool // Preserve original User Agent string
+	Mobile        bool
+	ClearCerts    bool
+	Private       bool
+	Scripted      bool
+	Lighthouse    bool
+}
+
+// UnmarshalJSON implements custom unmarshaling logic, because WebPagetest
+// returns numbers and flags both as numbers and as strings
+func (ti *TestInfo) UnmarshalJSON(b []byte) error {
+	var raw jsonTestInfo
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*ti = TestInfo{
+		ID:        raw.ID,
+		URL:       raw.URL,
+		Label:     raw.Label,
+		Location:  raw.Location,
+		Browser:   raw.Browser,
+		Runs:      int(raw.Runs),
+		Priority:  int(raw.Priority),
+		Owner:     raw.Owner,
+		Started:   int(raw.Started),
+		Completed: int(raw.Completed),
+
+		Connectivity:   raw.Connectivity,
+		BandwidthIn:    int(raw.BandwidthIn),
+		BandwidthOut:   int(raw.BandwidthOut),
+		Latency:        int(raw.Latency),
+		PacketLossRate: float64(raw.PacketLossRate),
+
+		Script:        raw.Script,
+		Block:         raw.Block,
+		BlockDomains:  raw.BlockDomains,
+		CustomMetrics: raw.CustomMetrics,
+		CustomHeaders: raw.CustomHeaders,
+		CmdLine:       raw.CmdLine,
+		InjectScript:  raw.InjectScript,
+
+		UAString:     raw.UAString,
+		AppendUA:     raw.AppendUA,
+		MobileDevice: raw.MobileDevice,
+		DPR:          float64(raw.DPR),
+		ScreenWidth:  int(raw.Width),
+		ScreenHeight: int(raw.Height),
+
+		MedianMetric:  raw.MedianMetric,
+		Tester:        raw.Tester,
+		Affinity:      raw.Affinity,
+		ImageQuality:  int(raw.ImageQuality),
+		Connections:   int(raw.Connections),
+		TimelineStack: int(raw.TimelineStack),
+
+		FirstViewOnly: bool(raw.FirstViewOnly),
+		Web10:         bool(raw.Web10),
+		IgnoreSSL:     bool(raw.IgnoreSSL),
+		Video:         bool(raw.Video),
+		MedianVideo:   bool(raw.MedianVideo),
+		Tcpdump:       bool(raw.Tcpdump),
+		Timeline:      bool(raw.Timeline),
+		Trace:         bool(raw.Trace),
+		Bodies:        bool(raw.Bodies),
+		HTMLBody:      bool(raw.HTMLBody),
+		NetLog:        bool(raw.NetLog),
+		Standards:     bool(raw.Standards),
+		NoScript:      bool(raw.NoScript),
+		NoOpt:         bool(raw.NoOpt),
+		NoImages:      bool(raw.NoImages),
+		NoHeaders:     bool(raw.NoHeaders),
+		Pngss:         bool(raw.Pngss),
+		KeepUA:        bool(raw.KeepUA),
+		Mobile:        bool(raw.Mobile),
+		ClearCerts:    bool(raw.ClearCerts),
+		Private:       bool(raw.Private),
+		Scripted:      bool(raw.Scripted) || raw.Script != "",
+		Lighthouse:    bool(raw.Lighthouse),
+	}
+
+	return nil
+}
+
+// GetTestInfo will retrieve full configuration of test, as it was submitted
+func (c *Client) GetTestInfo(testID string) (*TestInfo, error) {
+	body, err := c.query("/getgzip.php", url.Values{
+		"test": []string{testID},
+		"file": []string{"testinfo.json"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result TestInfo
+	if err = json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
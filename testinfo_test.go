@@ -0,0 +1,92 @@
+package webpagetest
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testInfoJSON = `{
+  "url": "http://google.com",
+  "runs": "3",
+  "fvonly": 1,
+  "web10": 0,
+  "ignoreSSL": "0",
+  "video": "on",
+  "label": "test run",
+  "location": "Prague",
+  "browser": "Chrome",
+  "connectivity": "Cable",
+  "bwIn": 5000,
+  "bwOut": "1000",
+  "latency": 28,
+  "plr": "0.5",
+  "dpr": 2.625,
+  "mobile": true,
+  "addCmdLine": "--disable-gpu",
+  "script": "navigate\thttp://google.com",
+  "scripted": 0
+}`
+
+func TestParsingTestInfo(t *testing.T) {
+	var info TestInfo
+	assert.Nil(t, json.Unmarshal([]byte(testInfoJSON), &info))
+
+	assert.Equal(t, 3, info.Runs)
+	assert.True(t, info.FirstViewOnly)
+	assert.False(t, info.Web10)
+	assert.False(t, info.IgnoreSSL)
+	assert.True(t, info.Video)
+	assert.True(t, info.Mobile)
+	assert.True(t, info.Scripted)
+	assert.Equal(t, 1000, info.BandwidthOut)
+	assert.Equal(t, 0.5, info.PacketLossRate)
+	assert.Equal(t, 2.625, info.DPR)
+	assert.Equal(t, "--disable-gpu", info.CmdLine)
+}
+
+func TestSettingsFromTestInfo(t *testing.T) {
+	var info TestInfo
+	assert.Nil(t, json.Unmarshal([]byte(testInfoJSON), &info))
+
+	settings := TestSettingsFromInfo(&info)
+	assert.Equal(t, "Prague:Chrome.Cable", settings.Location)
+	assert.Equal(t, 3, settings.Runs)
+	assert.True(t, settings.FirstViewOnly)
+	assert.True(t, settings.CaptureVideo)
+	assert.True(t, settings.Mobile)
+	assert.Equal(t, info.Script, settings.Script)
+	// Named profile defines bandwidth on server side
+	assert.Equal(t, 0, settings.BWDown)
+
+	info.Connectivity = "custom"
+	settings = TestSettingsFromInfo(&info)
+	assert.Equal(t, "Prague:Chrome.custom", settings.Location)
+	assert.Equal(t, 5000, settings.BWDown)
+	assert.Equal(t, 1000, settings.BWUp)
+	assert.Equal(t, 28, settings.Latency)
+	// Fractional values are kept as they were
+	assert.Equal(t, 2.625, settings.DPR)
+	assert.Equal(t, 0.5, settings.PacketLossRate)
+	assert.Equal(t, "2.625", settings.GetFormParams().Get("dpr"))
+	assert.Equal(t, "0.5", settings.GetFormParams().Get("plr"))
+
+	info.Location = "Prague:Chrome.custom"
+	info.Bodies, info.NetLog, info.Trace, info.Standards = true, true, true, true
+	info.InjectScript = "window.foo = 1;"
+	info.BlockDomains = "ads.example.com"
+	settings = TestSettingsFromInfo(&info)
+	assert.Equal(t, "Prague:Chrome.custom", settings.Location)
+	assert.True(t, settings.Bodies)
+	assert.True(t, settings.NetLog)
+	assert.True(t, settings.Trace)
+	assert.True(t, settings.Standards)
+	params := settings.GetFormParams()
+	assert.Equal(t, "window.foo = 1;", params.Get("injectScript"))
+	assert.Equal(t, "ads.example.com", params.Get("blockDomains"))
+	assert.Equal(t, "1", params.Get("bodies"))
+	assert.Equal(t, "1", params.Get("netlog"))
+	assert.Equal(t, "1", params.Get("trace"))
+	assert.Equal(t, "1", params.Get("standards"))
+}
@@ -3,6 +3,7 @@ package webpagetest
 import (
 	"fmt"
 	"net/url"
+	"strings"
 )
 
 // TestSettings is structure for describing what should be done in test run
@@ -67,7 +68,7 @@ type TestSettings struct {
 	// (optional) First-hop Round Trip Time in ms (used when specifying a custom connectivity profile)
 	Latency int `json:",omitempty"`
 	// (optional) Packet loss rate - percent of packets to drop (used when specifying a custom connectivity profile)
-	PacketLossRate int `json:",omitempty"`
+	PacketLossRate float64 `json:",omitempty"`
 	// (optional) (required for public instance)	API Key (if assigned) - applies only to runtest.php calls. Contact the site owner for a key if required (http://www.webpagetest.org/getkey.php for the public instance)
 	APIKey string `json:",omitempty"`
 	// (optional) Set to 1 to enable tcpdump capture	 0
@@ -89,7 +90,7 @@ type TestSettings struct {
 	// (optional) Custom User Agent String to use
 	UAString string `json:",omitempty"`
 	// (optional) Device Pixel Ratio to use when emulating mobile
-	DPR int `json:",omitempty"`
+	DPR float64 `json:",omitempty"`
 	// (optional) Set to 1 when capturing video to only store the video from the median run.	 0
 	MedianRunVideo bool `json:",omitempty"`
 	// (optional)  Custom command-line options (Chrome only)
@@ -112,6 +113,23 @@ type TestSettings struct {
 	// %CACHED% - Replaces with 1 for repeat view tests and 0 for initial view
 	// %VERSION% - Replaces with the current wptdriver version number
 	AppendUA string `json:",omitempty"`
+	// (optional) Set to 1 to run Lighthouse test alongside the test (Chrome only)
+	Lighthouse bool `json:",omitempty"`
+	// (optional) Set to 1 to save the content of all of the text responses (0)
+	Bodies bool `json:",omitempty"`
+	// (optional) Set to 1 to have Chrome capture the Network Log (0)
+	NetLog bool `json:",omitempty"`
+	// (optional) Set to 1 to capture Chrome Trace (about://tracing) (0)
+	Trace bool `json:",omitempty"`
+	// (optional) Set to 1 to disable Compatibility View (IE only) (0)
+	Standards bool `json:",omitempty"`
+	// (optional) JavaScript to run after the document has started loading
+	InjectScript string `json:",omitempty"`
+	// (optional) space-delimited list of domains to block
+	BlockDomains string `json:",omitempty"`
+
+	// Values of secrets used in Script, see ScriptTemplate
+	secrets *secretValues
 }
 
 // GetFormParams returns settings that was set ready to be passed to POST
@@ -148,6 +166,12 @@ func (s TestSettings) GetFormParams() url.Values {
 	if s.CustomHeaders != "" {
 		values.Add("customHeaders", s.CustomHeaders)
 	}
+	if s.InjectScript != "" {
+		values.Add("injectScript", s.InjectScript)
+	}
+	if s.BlockDomains != "" {
+		values.Add("blockDomains", s.BlockDomains)
+	}
 	if s.BWDown > 0 {
 		values.Add("bwDown", fmt.Sprintf("%d", s.BWDown))
 	}
@@ -158,7 +182,7 @@ func (s TestSettings) GetFormParams() url.Values {
 		values.Add("latency", fmt.Sprintf("%d", s.Latency))
 	}
 	if s.PacketLossRate > 0 {
-		values.Add("plr", fmt.Sprintf("%d", s.PacketLossRate))
+		values.Add("plr", fmt.Sprintf("%g", s.PacketLossRate))
 	}
 
 	// bool
@@ -213,6 +237,21 @@ func (s TestSettings) GetFormParams() url.Values {
 	if s.IgnoreSSL {
 		values.Add("ignoreSSL", "1")
 	}
+	if s.Lighthouse {
+		values.Add("lighthouse", "1")
+	}
+	if s.Bodies {
+		values.Add("bodies", "1")
+	}
+	if s.NetLog {
+		values.Add("netlog", "1")
+	}
+	if s.Trace {
+		values.Add("trace", "1")
+	}
+	if s.Standards {
+		values.Add("standards", "1")
+	}
 	if s.ImageQuality > 0 {
 		values.Add("iq", fmt.Sprintf("%d", s.ImageQuality))
 	}
@@ -223,8 +262,81 @@ func (s TestSettings) GetFormParams() url.Values {
 		values.Add("connections", fmt.Sprintf("%d", s.Connections))
 	}
 	if s.DPR > 0 {
-		values.Add("dpr", fmt.Sprintf("%d", s.DPR))
+		values.Add("dpr", fmt.Sprintf("%g", s.DPR))
 	}
 
 	return values
 }
+
+// TestSettingsFromInfo builds TestSettings, that will run test exactly as it
+// was described by given TestInfo (from GetTestStatus or GetTestInfo)
+func TestSettingsFromInfo(info *TestInfo) TestSettings {
+	location := info.Location
+	if info.Browser != "" && !strings.Contains(location, ":") {
+		location += ":" + info.Browser
+	}
+	if info.Connectivity != "" && !strings.HasSuffix(location, "."+info.Connectivity) {
+		location += "." + info.Connectivity
+	}
+
+	settings := TestSettings{
+		URL:      info.URL,
+		Label:    info.Label,
+		Location: location,
+		Runs:     info.Runs,
+
+		ScreenWidth:   info.ScreenWidth,
+		ScreenHeight:  info.ScreenHeight,
+		MedianMetric:  info.MedianMetric,
+		Script:        info.Script,
+		CustomHeaders: info.CustomHeaders,
+		Block:         info.Block,
+		BlockDomains:  info.BlockDomains,
+		InjectScript:  info.InjectScript,
+		CmdLine:       info.CmdLine,
+		CustomMetrics: info.CustomMetrics,
+		Tester:        info.Tester,
+		Affinity:      info.Affinity,
+		UAString:      info.UAString,
+		AppendUA:      info.AppendUA,
+		MobileDevice:  info.MobileDevice,
+		DPR:           info.DPR,
+		ImageQuality:  info.ImageQuality,
+		Connections:   info.Connections,
+		TimelineStack: info.TimelineStack,
+
+		Timeline:       info.Timeline,
+		FirstViewOnly:  info.FirstViewOnly,
+		Private:        info.Private,
+		CaptureVideo:   info.Video,
+		MedianRunVideo: info.MedianVideo,
+		PNGScreenShot:  info.Pngss,
+		Web10:          info.Web10,
+		TCPDump:        info.Tcpdump,
+		NoOpt:          info.NoOpt,
+		NoImages:       info.NoImages,
+		NoHeaders:      info.NoHeaders,
+		NoScript:       info.NoScript,
+		ClearCerts:     info.ClearCerts,
+		Mobile:         info.Mobile,
+		KeepUA:         info.KeepUA,
+		HTMLBody:       info.HTMLBody,
+		IgnoreSSL:      info.IgnoreSSL,
+		Lighthouse:     info.Lighthouse,
+		Bodies:         info.Bodies,
+		NetLog:         info.NetLog,
+		Trace:          info.Trace,
+		Standards:      info.Standards,
+	}
+
+	// Bandwidth, latency and packet loss can only be set for custom profile,
+	// named profiles define them on the server
+	if info.Connectivity == "" || strings.EqualFold(info.Connectivity, "custom") {
+		settings.BWDown = info.BandwidthIn
+		settings.BWUp = info.BandwidthOut
+		settings.Latency = info.Latency
+		settings.PacketLossRate = info.PacketLossRate
+	}
+
+	return settings
+}
@@ -1,7 +1,6 @@
 package webpagetest
 
 import (
-	"encoding/json"
 	"fmt"
 )
 
@@ -16,12 +15,11 @@ type Connectivity struct {
 	// First-hop Round Trip Time in ms
 	Latency int `json:"latency"`
 	// Packet loss rate - percent of packets to drop
-	RawPacketLossRate *json.RawMessage `json:"plr"`
-	PacketLossRate    int
+	PacketLossRate float64 `json:"plr"`
 }
 
 // String gives human readable string for connectivity profile
 func (c Connectivity) String() string {
-	return fmt.Sprintf("%v (%dKbps/%dKbps) %vms, Packet Loss %d%%",
+	return fmt.Sprintf("%v (%dKbps/%dKbps) %vms, Packet Loss %v%%",
 		c.Name, c.BandwidthDown, c.BandwidthUp, c.Latency, c.PacketLossRate)
 }
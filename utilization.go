@@ -0,0 +1,197 @@
+package webpagetest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+/*
+Offset Time (ms),Bandwidth In (bps),CPU Utilization (%),Memory Use (KB)
+100,0,18.75,107484
+200,154536,50.00,109652
+300,1250400,93.75,118236
+...
+*/
+
+// UtilizationSample is one measurement of agent's resources during test
+type UtilizationSample struct {
+	// Offset from the start of the test (ms)
+	Time int
+	// Incoming bandwidth (bps)
+	BandwidthIn int
+	// CPU Utilization (%)
+	CPU float64
+	// Memory Use (KB)
+	Memory int
+}
+
+// Utilization is time series of CPU, bandwidth and memory utilization of one test step
+type Utilization []UtilizationSample
+
+// TimeWindow is period of time during test, in ms from the start of the test
+type TimeWindow struct {
+	Start int
+	End   int
+}
+
+// Duration of window in ms
+func (w TimeWindow) Duration() int {
+	return w.End - w.Start
+}
+
+// CPUIdleWindows returns all periods of at least minDuration ms, when CPU utilization
+// was below threshold (in %)
+func (u Utilization) CPUIdleWindows(threshold float64, minDuration int) []TimeWindow {
+	result := make([]TimeWindow, 0)
+	start := -1
+	prevTime := 0
+	for _, sample := range u {
+		if sample.CPU < threshold {
+			if start < 0 {
+				start = prevTime
+			}
+		} else if start >= 0 {
+			if prevTime-start >= minDuration {
+				result = append(result, TimeWindow{Start: start, End: prevTime})
+			}
+			start = -1
+		}
+		prevTime = sample.Time
+	}
+	if start >= 0 && prevTime-start >= minDuration {
+		result = append(result, TimeWindow{Start: start, End: prevTime})
+	}
+	return result
+}
+
+// BandwidthSaturation returns average share (0..1) of available download bandwidth
+// (in Kbps, like Connectivity.BandwidthDown) that was used until given time (ms),
+// for example until StartRender
+func (u Utilization) BandwidthSaturation(bandwidthDown int, until int) float64 {
+	if bandwidthDown <= 0 {
+		return 0
+	}
+	var total float64
+	var count int
+	for _, sample := range u {
+		if sample.Time > until {
+			break
+		}
+		total += float64(sample.BandwidthIn) / float64(bandwidthDown*1000)
+		count++
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
+
+// MaxCPU returns peak CPU utilization (in %)
+func (u Utilization) MaxCPU() float64 {
+	var result float64
+	for _, sample := range u {
+		if sample.CPU > result {
+			result = sample.CPU
+		}
+	}
+	return result
+}
+
+// GetUtilization will retrieve CPU, bandwidth and memory utilization of given run, view (cached or not) and step.
+// Run and step are 1-based
+func (c *Client) GetUtilization(testID string, run int, cached bool, step int) (Utilization, error) {
+	body, err := c.query("/getgzip.php", url.Values{
+		"test": []string{testID},
+		"file": []string{resultFileName(run, cached, step, "progress.csv")},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return parseUtilization(bytes.NewReader(body))
+}
+
+func parseUtilization(r io.Reader) (Utilization, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read utilization header: %v", err)
+	}
+
+	columns := map[string]int{"time": -1, "bw": -1, "cpu": -1, "mem": -1}
+	for idx, name := range header {
+		name = strings.ToLower(name)
+		switch {
+		case strings.HasPrefix(name, "offset time"):
+			columns["time"] = idx
+		case strings.HasPrefix(name, "bandwidth in"):
+			columns["bw"] = idx
+		case strings.HasPrefix(name, "cpu"):
+			columns["cpu"] = idx
+		case strings.HasPrefix(name, "memory"):
+			columns["mem"] = idx
+		}
+	}
+	if columns["time"] < 0 {
+		return nil, fmt.Errorf("unexpected utilization header: %v", header)
+	}
+
+	result := make(Utilization, 0)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var sample UtilizationSample
+		if sample.Time, err = strconv.Atoi(csvField(record, columns["time"])); err != nil {
+			return nil, fmt.Errorf("bad time in utilization line %d: %v", line, err)
+		}
+		if sample.BandwidthIn, err = csvInt(record, columns["bw"]); err != nil {
+			return nil, fmt.Errorf("bad bandwidth in utilization line %d: %v", line, err)
+		}
+		if sample.CPU, err = csvFloat(record, columns["cpu"]); err != nil {
+			return nil, fmt.Errorf("bad cpu in utilization line %d: %v", line, err)
+		}
+		if sample.Memory, err = csvInt(record, columns["mem"]); err != nil {
+			return nil, fmt.Errorf("bad memory in utilization line %d: %v", line, err)
+		}
+		result = append(result, sample)
+	}
+
+	return result, nil
+}
+
+func csvField(record []string, idx int) string {
+	if idx < 0 || idx >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[idx])
+}
+
+// csvInt returns numeric field of record, missing and empty fields are 0
+func csvInt(record []string, idx int) (int, error) {
+	value := csvField(record, idx)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// csvFloat returns numeric field of record, missing and empty fields are 0
+func csvFloat(record []string, idx int) (float64, error) {
+	value := csvField(record, idx)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(value, 64)
+}
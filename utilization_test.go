@@ -0,0 +1,46 @@
+package webpagetest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testUtilization = `Offset Time (ms),Bandwidth In (bps),CPU Utilization (%),Memory Use (KB)
+100,0,10.00,100
+200,1000000,95.00,110
+300,2000000,90.00,120
+400,0,5.00,120
+500,0,0.00,120
+600,0,3.00,120
+700,500000,80.00,130
+`
+
+func TestParsingUtilization(t *testing.T) {
+	utilization, err := parseUtilization(strings.NewReader(testUtilization))
+	assert.Nil(t, err)
+	assert.Len(t, utilization, 7)
+	assert.Equal(t, UtilizationSample{Time: 200, BandwidthIn: 1000000, CPU: 95, Memory: 110}, utilization[1])
+	assert.Equal(t, 95.0, utilization.MaxCPU())
+
+	assert.Equal(t, []TimeWindow{{Start: 300, End: 600}}, utilization.CPUIdleWindows(10, 200))
+	assert.Equal(t, []TimeWindow{{Start: 0, End: 100}, {Start: 300, End: 600}}, utilization.CPUIdleWindows(20, 100))
+
+	// 5000Kbps link: 0, 20% and 40% used until 300ms
+	assert.InDelta(t, 0.2, utilization.BandwidthSaturation(5000, 300), 0.0001)
+}
+
+func TestParsingUtilizationWithBadHeader(t *testing.T) {
+	_, err := parseUtilization(strings.NewReader("foo,bar\n1,2\n"))
+	assert.NotNil(t, err)
+}
+
+func TestParsingUtilizationWithBadValue(t *testing.T) {
+	_, err := parseUtilization(strings.NewReader(testUtilization + "800,100,n/a,130\n"))
+	assert.EqualError(t, err, `bad cpu in utilization line 9: strconv.ParseFloat: parsing "n/a": invalid syntax`)
+
+	utilization, err := parseUtilization(strings.NewReader("Offset Time (ms),CPU Utilization (%)\n100,\n200,50\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, Utilization{{Time: 100}, {Time: 200, CPU: 50}}, utilization)
+}
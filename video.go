@@ -0,0 +1,155 @@
+package webpagetest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// VideoSpec is one test (run, view and step of it) to be included in comparison video
+type VideoSpec struct {
+	TestID string
+	// Run number, 1-based, 0 means median run
+	Run int
+	// Repeat view instead of first view
+	Cached bool
+	// Step number, 1-based (1)
+	Step int
+	// Label to show above the video, test's label by default
+	Label string
+}
+
+func (s VideoSpec) String() string {
+	spec := s.TestID
+	if s.Run > 0 {
+		spec += fmt.Sprintf("-r:%d", s.Run)
+	}
+	if s.Cached {
+		spec += "-c:1"
+	}
+	if s.Step > 1 {
+		spec += fmt.Sprintf("-s:%d", s.Step)
+	}
+	if s.Label != "" {
+		// "," and "-" are separators in spec, so they can't be used in label
+		spec += "-l:" + strings.NewReplacer(",", " ", "-", " ").Replace(s.Label)
+	}
+	return spec
+}
+
+// VideoOptions is options for comparison video rendering
+type VideoOptions struct {
+	// When to end the video: "visual" (visually complete, default), "doc" (document complete),
+	// "full" (fully loaded) or "all" (last change of all tests)
+	End string
+	// How often to check if video is ready (5s)
+	PollInterval time.Duration
+	// How long to wait for video to be rendered (5m)
+	Timeout time.Duration
+}
+
+// ComparisonVideo is rendered video
+type ComparisonVideo struct {
+	ID          string
+	DownloadURL string
+	EmbedURL    string
+}
+
+type jsonVideoResponse struct {
+	StatusCode int    `json:"statusCode"`
+	StatusText string `json:"statusText"`
+	Data       struct {
+		VideoID  string `json:"videoId"`
+		VideoURL string `json:"videoUrl"`
+	} `json:"data"`
+}
+
+// CreateComparisonVideo will ask server to render side by side video of given tests
+// and will wait for it to be ready, until options.Timeout passes or ctx is done
+func (c *Client) CreateComparisonVideo(ctx context.Context, tests []VideoSpec, options VideoOptions) (*ComparisonVideo, error) {
+	if len(tests) == 0 {
+		return nil, fmt.Errorf("no tests to compare")
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = 5 * time.Second
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = 5 * time.Minute
+	}
+
+	specs := make([]string, 0, len(tests))
+	for _, test := range tests {
+		specs = append(specs, test.String())
+	}
+	params := url.Values{
+		"f":     []string{"json"},
+		"tests": []string{strings.Join(specs, ",")},
+	}
+	if options.End != "" {
+		params.Set("end", options.End)
+	}
+
+	body, err := c.queryContext(ctx, "/video/create.php", params)
+	if err != nil {
+		return nil, err
+	}
+	var created jsonVideoResponse
+	if err = json.Unmarshal(body, &created); err != nil {
+		return nil, err
+	}
+	if created.StatusCode != 200 || created.Data.VideoID == "" {
+		return nil, fmt.Errorf("failed to create video: %v: %v", created.StatusCode, created.StatusText)
+	}
+
+	video := ComparisonVideo{
+		ID:          created.Data.VideoID,
+		DownloadURL: c.Host + "/video/download.php?" + url.Values{"id": []string{created.Data.VideoID}}.Encode(),
+		EmbedURL:    c.GetEmbedVideoPlayerURL(created.Data.VideoID),
+	}
+
+	timeout := time.NewTimer(options.Timeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(options.PollInterval)
+	defer ticker.Stop()
+	for {
+		body, err := c.queryContext(ctx, "/video/view.php", url.Values{
+			"f":  []string{"json"},
+			"id": []string{video.ID},
+		})
+		if err != nil {
+			return nil, err
+		}
+		var status jsonVideoResponse
+		if err = json.Unmarshal(body, &status); err != nil {
+			return nil, err
+		}
+		if status.StatusCode > 200 {
+			return nil, fmt.Errorf("failed to render video %s: %v: %v", video.ID, status.StatusCode, status.StatusText)
+		}
+		if status.StatusCode == 200 {
+			if status.Data.VideoURL != "" {
+				video.DownloadURL = status.Data.VideoURL
+			}
+			return &video, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			return nil, fmt.Errorf("video %s is not ready after %v", video.ID, options.Timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetEmbedVideoPlayerURL returns URL of embeddable player for video with given ID
+func (c *Client) GetEmbedVideoPlayerURL(videoID string) string {
+	return c.Host + "/video/view.php?" + url.Values{
+		"embed": []string{"1"},
+		"id":    []string{videoID},
+	}.Encode()
+}
@@ -0,0 +1,83 @@
+package webpagetest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVideoSpec(t *testing.T) {
+	assert.Equal(t, "170101_AB_1", VideoSpec{TestID: "170101_AB_1"}.String())
+	assert.Equal(t, "170101_AB_1-r:3-c:1-s:2-l:New design  v2",
+		VideoSpec{TestID: "170101_AB_1", Run: 3, Cached: true, Step: 2, Label: "New design, v2"}.String())
+}
+
+func testVideoServer(t *testing.T, polls int, status string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/video/create.php":
+			assert.Equal(t, "170101_AB_1-r:1,170101_CD_2", r.URL.Query().Get("tests"))
+			assert.Equal(t, "full", r.URL.Query().Get("end"))
+			fmt.Fprint(w, `{"statusCode": 200, "statusText": "Ok", "data": {"videoId": "170101_video"}}`)
+		case "/video/view.php":
+			assert.Equal(t, "170101_video", r.URL.Query().Get("id"))
+			if polls > 0 {
+				polls--
+				fmt.Fprint(w, `{"statusCode": 100, "statusText": "Rendering"}`)
+				return
+			}
+			fmt.Fprint(w, status)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+var testVideoSpecs = []VideoSpec{{TestID: "170101_AB_1", Run: 1}, {TestID: "170101_CD_2"}}
+
+func TestCreatingComparisonVideo(t *testing.T) {
+	server := testVideoServer(t, 2, `{"statusCode": 200, "statusText": "Ok", "data": {"videoUrl": "http://example.com/video.mp4"}}`)
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+
+	video, err := client.CreateComparisonVideo(context.Background(), testVideoSpecs,
+		VideoOptions{End: "full", PollInterval: time.Millisecond})
+	assert.Nil(t, err)
+	assert.Equal(t, &ComparisonVideo{
+		ID:          "170101_video",
+		DownloadURL: "http://example.com/video.mp4",
+		EmbedURL:    server.URL + "/video/view.php?embed=1&id=170101_video",
+	}, video)
+}
+
+func TestFailedComparisonVideo(t *testing.T) {
+	server := testVideoServer(t, 1, `{"statusCode": 404, "statusText": "Invalid test"}`)
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+
+	_, err := client.CreateComparisonVideo(context.Background(), testVideoSpecs,
+		VideoOptions{End: "full", PollInterval: time.Millisecond})
+	assert.EqualError(t, err, "failed to render video 170101_video: 404: Invalid test")
+}
+
+func TestComparisonVideoTimeout(t *testing.T) {
+	server := testVideoServer(t, 1000, "")
+	defer server.Close()
+	client, _ := NewClient(server.URL)
+
+	_, err := client.CreateComparisonVideo(context.Background(), testVideoSpecs,
+		VideoOptions{End: "full", PollInterval: time.Millisecond, Timeout: 20 * time.Millisecond})
+	assert.EqualError(t, err, "video 170101_video is not ready after 20ms")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.CreateComparisonVideo(ctx, testVideoSpecs,
+		VideoOptions{End: "full", PollInterval: time.Millisecond})
+	assert.NotNil(t, err)
+	assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+}
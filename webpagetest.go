@@ -2,8 +2,10 @@ package webpagetest
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -13,6 +15,10 @@ import (
 // Client is client of WebPageTest
 type Client struct {
 	Host string
+	// API Key, that will be used for tests, that don't have their own
+	APIKey string
+	// Check that API key has enough test units left before submitting tests
+	CheckQuota bool
 }
 
 // NewClient returns new ready to use Client
@@ -50,23 +56,67 @@ func (c *Client) CancelTest(testID string) error {
 }
 
 func (c *Client) query(api string, params url.Values) ([]byte, error) {
+	return c.queryContext(context.Background(), api, params)
+}
+
+func (c *Client) queryContext(ctx context.Context, api string, params url.Values) ([]byte, error) {
 	// http://www.webpagetest.org/cancelTest.php?test=<testId>&k=<API key>
-	queryURL := c.Host + api + "?" + params.Encode()
-	resp, err := http.Get(queryURL)
+	body, err := c.download(ctx, api, params)
 	if err != nil {
-		return nil, fmt.Errorf("failed to GET \"%s\": %v", queryURL, err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	defer body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	return ioutil.ReadAll(body)
+}
+
+// statusError is returned by query and download, when server responds with status other than 200
+type statusError struct {
+	Code int
+	Body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("Status is no OK: %v [%v]", e.Code, e.Body)
+}
+
+// download is like query, but returns response body as is, so it can be streamed.
+// Caller is responsible for closing it
+func (c *Client) download(ctx context.Context, api string, params url.Values) (io.ReadCloser, error) {
+	queryURL := c.Host + api + "?" + params.Encode()
+	req, err := http.NewRequest(http.MethodGet, queryURL, nil)
 	if err != nil {
 		return nil, err
 	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to GET \"%s\": %v", queryURL, err)
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("Status is no OK: %v [%v]", resp.StatusCode, string(body))
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, &statusError{Code: resp.StatusCode, Body: string(body)}
 	}
 
-	return body, nil
+	return resp.Body, nil
+}
+
+// resultFileName builds name of test result file for given run, view and step,
+// as WebPagetest stores them: "<run>[_Cached][_<step>]_<name>"
+func resultFileName(run int, cached bool, step int, name string) string {
+	return resultFilePrefix(run, cached, step) + "_" + name
+}
+
+// resultFilePrefix is "<run>[_Cached][_<step>]" part of test result file name
+func resultFilePrefix(run int, cached bool, step int) string {
+	prefix := fmt.Sprintf("%d", run)
+	if cached {
+		prefix += "_Cached"
+	}
+	if step > 1 {
+		prefix += fmt.Sprintf("_%d", step)
+	}
+	return prefix
 }
 
 /*
@@ -86,7 +136,22 @@ func (c *Client) query(api string, params url.Values) ([]byte, error) {
 */
 
 // RunTest will submit given test to WPT server
+// If CheckQuota is set, it will first check that API key has enough test units left and
+// will return *QuotaError without submitting test otherwise
 func (c *Client) RunTest(settings TestSettings) (string, error) {
+	if settings.APIKey == "" {
+		settings.APIKey = c.APIKey
+	}
+	if c.CheckQuota {
+		if err := c.checkQuota(settings.APIKey, settings.Cost()); err != nil {
+			return "", err
+		}
+	}
+	testID, err := c.submitTest(settings)
+	return testID, settings.redactError(err)
+}
+
+func (c *Client) submitTest(settings TestSettings) (string, error) {
 	resp, err := http.PostForm(c.Host+"/runtest.php", settings.GetFormParams())
 	if err != nil {
 		return "", err
@@ -122,6 +187,21 @@ func (c *Client) RunTest(settings TestSettings) (string, error) {
 	return result.Data.TestID, nil
 }
 
+// RerunTest will submit new test with exactly same settings as test with given testID had.
+// Settings can be changed with overrides before submit, for example to set APIKey
+func (c *Client) RerunTest(testID string, overrides func(*TestSettings)) (string, error) {
+	info, err := c.GetTestInfo(testID)
+	if err != nil {
+		return "", err
+	}
+
+	settings := TestSettingsFromInfo(info)
+	if overrides != nil {
+		overrides(&settings)
+	}
+	return c.RunTest(settings)
+}
+
 // StatusCallback is helper type for function to be called while waiting for test to complete
 type StatusCallback func(testID, status string, duration int)
 
@@ -158,19 +238,8 @@ func (c *Client) RunTestAndWait(settings TestSettings, callback StatusCallback)
 }
 
 // getHARData(id, options, callback)
-// getPageSpeedData(id, options, callback)
-// getUtilizationData(id, options, callback)
 // getRequestData(id, options, callback)
 // getTimelineData(id, options, callback)
 // getNetLogData(id, options, callback)
 // getChromeTraceData(id, options, callback)
-// getConsoleLogData(id, options, callback)
-// getTestInfo(id, options, callback)
-// getHistory(days, options, callback)
 // getGoogleCsiData(id, options, callback)
-// getResponseBody(id, options, callback)
-// getWaterfallImage(id, options, callback)
-// getScreenshotImage(id, options, callback)
-// createVideo(tests, options, callback)
-// getEmbedVideoPlayer(id, options, callback)
-// scriptToString(script)